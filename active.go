@@ -0,0 +1,115 @@
+package main
+
+// activeCols is the number of cells of a span, which is the unit of the
+// active region of Life.
+const activeCols = 4
+
+// activeTrackable reports whether Next can calculate only the active
+// region, which is around the cells which changed from the previous
+// generation. Cells depend only on their neighbors within one cell, and
+// change only by their own changes except for ages and activity.
+func (l *Life) activeTrackable() bool {
+	r := l.cur.rule
+	return r.radius == 0 && !r.elementary && l.cur.ages == nil && l.cur.heat == nil
+}
+
+// newSpans returns flags of the spans of h rows of w cells.
+func newSpans(h, w int) [][]bool {
+	n := (w + activeCols - 1) / activeCols
+	buf := make([]bool, h*n)
+	spans := make([][]bool, h)
+	for i := range spans {
+		spans[i] = buf[i*n : (i+1)*n]
+	}
+	return spans
+}
+
+// prepareActive clears the spans changing in next generation, and drops
+// the active region unless it was calculated for current generation.
+func (l *Life) prepareActive() {
+	if !l.activeTrackable() {
+		l.active, l.changed, l.activeFor = nil, nil, nil
+		return
+	}
+	if l.activeFor != l.cur || len(l.active) != l.cur.h {
+		l.active = nil
+	}
+	if len(l.changed) != l.cur.h || (l.cur.h > 0 && len(l.changed[0]) != (l.cur.w+activeCols-1)/activeCols) {
+		l.changed = newSpans(l.cur.h, l.cur.w)
+		return
+	}
+	for _, row := range l.changed {
+		for s := range row {
+			row[s] = false
+		}
+	}
+}
+
+// copyRow copies row i of current generation to next generation.
+func (l *Life) copyRow(i int) {
+	if l.cur.bits != nil {
+		copy(l.next.bits[i], l.cur.bits[i])
+	} else {
+		copy(l.next.cs[i], l.cur.cs[i])
+	}
+	if l.cur.dying != nil {
+		copy(l.next.dying[i], l.cur.dying[i])
+	}
+}
+
+// markChanged marks the spans of row i holding the cell at column j, which
+// changes in next generation, and its left and right neighbors.
+func (l *Life) markChanged(i, j int) {
+	row := l.changed[i]
+	row[j/activeCols] = true
+	w := l.cur.w
+	switch {
+	case j%activeCols == 0 && j > 0:
+		row[(j-1)/activeCols] = true
+	case j == 0 && l.cur.topo == Torus:
+		row[(w-1)/activeCols] = true
+	}
+	switch {
+	case j+1 < w && (j+1)%activeCols == 0:
+		row[(j+1)/activeCols] = true
+	case j+1 == w && l.cur.topo == Torus:
+		row[0] = true
+	}
+}
+
+// spreadActive sets the active region of current generation to the spans
+// marked by markChanged and the same spans of the rows above and below,
+// wrapping around top and bottom edges of torus.
+func (l *Life) spreadActive() {
+	if l.changed == nil {
+		return
+	}
+	h := len(l.changed)
+	if l.active == nil {
+		l.active = newSpans(h, l.cur.w)
+	} else {
+		for _, row := range l.active {
+			for s := range row {
+				row[s] = false
+			}
+		}
+	}
+	wrap := l.cur.topo == Torus
+	for i, row := range l.changed {
+		for s, on := range row {
+			if !on {
+				continue
+			}
+			for di := -1; di <= 1; di++ {
+				r := i + di
+				if wrap {
+					r = (r + h) % h
+				} else if r < 0 || r >= h {
+					continue
+				}
+				l.active[r][s] = true
+			}
+		}
+	}
+	l.activeFor = l.cur
+}
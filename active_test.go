@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func TestActiveRegionCrossCheck(t *testing.T) {
+	rules := []string{"B3/S23", "B36/S23", "B2/S/C3", "B2/S34H", "B1/S12V", "B0/S8"}
+	sizes := [][2]int{{3, 5}, {33, 65}, {40, 31}, {70, 100}}
+	for seed := int64(0); seed < 3; seed++ {
+		for _, rule := range rules {
+			r, err := ParseRule(rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, topo := range []Topology{Torus, Fixed, Mirror} {
+				for _, size := range sizes {
+					l, err := NewRandomLife(size[0], size[1], 0.3, seed)
+					if err != nil {
+						t.Fatal(err)
+					}
+					l.SetRule(r)
+					l.SetTopology(topo)
+					l.SetWorkers(1)
+					for g := 0; g < 40; g++ {
+						// Clone drops the active region, so ref scans the whole field.
+						ref := l.Clone()
+						wantB, wantD := ref.Step()
+						b, d := l.Step()
+						if !equalStates(l.cur, ref.cur) || b != wantB || d != wantD {
+							t.Fatalf("%v %v %dx%d seed %d: generation %d differs from full scan",
+								rule, topo, size[0], size[1], seed, l.gen)
+						}
+					}
+					if l.active == nil {
+						t.Fatalf("%v: active region is not tracked", rule)
+					}
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkActiveRegion compares Next calculating only the active region
+// and the whole field, on a 512x512 soup settled into ash.
+func BenchmarkActiveRegion(b *testing.B) {
+	l, err := NewRandomLife(512, 512, 0.3, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	l.SetWorkers(1)
+	l.Run(12000)
+	b.Run("active", func(b *testing.B) {
+		c := l.Clone()
+		c.Next()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			c.Next()
+		}
+	})
+	b.Run("full", func(b *testing.B) {
+		c := l.Clone()
+		for i := 0; i < b.N; i++ {
+			c.activeFor = nil
+			c.Next()
+		}
+	})
+}
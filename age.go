@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"math/bits"
+	"strconv"
+)
+
+// newAges returns ages of h x w cells, all zero.
+func newAges(h, w int) [][]int {
+	buf := make([]int, h*w)
+	ages := make([][]int, h)
+	for i := range ages {
+		ages[i] = buf[i*w : (i+1)*w]
+	}
+	return ages
+}
+
+// Age returns the number of consecutive generations the cell of r & c has
+// been alive, which is 1 for newborn cells and 0 for dead cells. It is 0 for
+// all cells unless ages are tracked, which is done in Color render mode.
+func (f *Field) Age(r, c int) int {
+	if f.ages == nil {
+		return 0
+	}
+	return f.ages[r][c]
+}
+
+// trackAges starts tracking ages of cells. Live cells of current generation
+// are treated as newborn.
+func (l *Life) trackAges() {
+	l.cur.ages = newAges(l.cur.h, l.cur.w)
+	for i := 0; i < l.cur.h; i++ {
+		for j := 0; j < l.cur.w; j++ {
+			if l.cur.get(i, j) {
+				l.cur.ages[i][j] = 1
+			}
+		}
+	}
+	l.next.ages = newAges(l.next.h, l.next.w)
+	l.clearHistory()
+}
+
+// ageColors is the gradient of ANSI 256 colors from newborn cells in bright
+// yellow to long-lived cells in blue. The bucket of age n is log2(n).
+var ageColors = []int{226, 220, 214, 178, 142, 106, 70, 34, 36, 31, 25, 19}
+
+// ageColor returns the color of cells of age.
+func ageColor(age int) int {
+	i := bits.Len(uint(age)) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(ageColors) {
+		i = len(ageColors) - 1
+	}
+	return ageColors[i]
+}
+
+// ansiReset resets the color of text.
+const ansiReset = "\x1b[0m"
+
+// renderColor appends the field to buf with live cells colored by age.
+// The color is reset before dead cells and at the end of each line, so that
+// dead cells are drawn in the default color.
+func (f *Field) renderColor(buf *bytes.Buffer, g Glyphs) {
+	for i := 0; i < f.h; i++ {
+		color := -1
+		for j := 0; j < f.w; j++ {
+			if !f.get(i, j) {
+				if color >= 0 {
+					buf.WriteString(ansiReset)
+					color = -1
+				}
+				buf.WriteRune(g.Dead)
+				continue
+			}
+			if c := ageColor(f.Age(i, j)); c != color {
+				buf.WriteString("\x1b[38;5;" + strconv.Itoa(c) + "m")
+				color = c
+			}
+			buf.WriteRune(g.Alive)
+		}
+		if color >= 0 {
+			buf.WriteString(ansiReset)
+		}
+		buf.WriteByte('\n')
+	}
+}
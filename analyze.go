@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// analyzeGenerations is the default number of generations run by analyze.
+const analyzeGenerations = 1000
+
+// Box is a rectangle of cells.
+type Box struct {
+	Top    int `json:"top"`
+	Left   int `json:"left"`
+	Height int `json:"height"`
+	Width  int `json:"width"`
+}
+
+// Report is the result of Analyze.
+type Report struct {
+	Generations       int  `json:"generations"` // generations run
+	InitialPopulation int  `json:"initial_population"`
+	FinalPopulation   int  `json:"final_population"`
+	PeakPopulation    int  `json:"peak_population"`
+	PeakGeneration    int  `json:"peak_generation"`
+	BoundingBox       Box  `json:"bounding_box"` // of live cells of the final generation
+	Stable            bool `json:"stable"`       // whether the pattern became a still life or an oscillator
+	StableGeneration  int  `json:"stable_generation"`
+	Period            int  `json:"period"`
+	Extinct           bool `json:"extinct"`
+}
+
+// Analyze runs l headlessly by at most maxGen generations, until it becomes
+// a still life or an oscillator, or dies out. StableGeneration of the report
+// is the first generation of the cycle, and an empty field is a still life.
+func (l *Life) Analyze(maxGen int) Report {
+	start := l.gen
+	r := Report{InitialPopulation: l.Population(), PeakPopulation: -1}
+	var d cycleDetector
+	for {
+		pop := l.Population()
+		if pop > r.PeakPopulation {
+			r.PeakPopulation, r.PeakGeneration = pop, l.gen
+		}
+		if l.extinct() {
+			r.Stable, r.StableGeneration, r.Period, r.Extinct = true, l.gen, 1, true
+			break
+		}
+		if p := d.observe(l); p > 0 {
+			r.Stable, r.StableGeneration, r.Period = true, l.gen-p, p
+			break
+		}
+		if l.gen-start >= maxGen {
+			break
+		}
+		l.Next()
+	}
+	r.Generations = l.gen - start
+	r.FinalPopulation = l.Population()
+	if minR, minC, maxR, maxC, ok := l.cur.BoundingBox(); ok {
+		r.BoundingBox = Box{Top: minR, Left: minC, Height: maxR - minR + 1, Width: maxC - minC + 1}
+	}
+	return r
+}
+
+// Fprint writes the report in human readable form to w.
+func (r Report) Fprint(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "generations:  %d\n", r.Generations)
+	fmt.Fprintf(bw, "population:   %d -> %d, peak %d at generation %d\n",
+		r.InitialPopulation, r.FinalPopulation, r.PeakPopulation, r.PeakGeneration)
+	b := r.BoundingBox
+	fmt.Fprintf(bw, "bounding box: %dx%d at (%d, %d)\n", b.Width, b.Height, b.Left, b.Top)
+	switch {
+	case !r.Stable:
+		fmt.Fprintf(bw, "stable:       no\n")
+	case r.Period == 1:
+		fmt.Fprintf(bw, "stable:       still life at generation %d\n", r.StableGeneration)
+	default:
+		fmt.Fprintf(bw, "stable:       period %d from generation %d\n", r.Period, r.StableGeneration)
+	}
+	if r.Extinct {
+		fmt.Fprintf(bw, "died out:     yes\n")
+	} else {
+		fmt.Fprintf(bw, "died out:     no\n")
+	}
+	return bw.Flush()
+}
+
+// analyzeMain runs analyze subcommand with args following "analyze":
+//
+//	lifegame analyze [-generations n] [-json] pattern
+//
+// The pattern is a file, URL or - for stdin, and flags may follow it.
+func analyzeMain(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	generations := flags.Int("generations", analyzeGenerations, "maximum number of `generations` to run")
+	jsonFlag := flags.Bool("json", false, "write the report as JSON")
+	pattern := flags.String("pattern", "", "analyze the built-in `name`d pattern")
+	ruleFlag := flags.String("rule", "", "`rule` overriding the rule of the pattern")
+	grow := flags.Int("grow", 0, "grow the field when live cells are within `margin` cells of its edges")
+	var files []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		files = append(files, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(files) > 1 || (len(files) == 1) == (*pattern != "") {
+		return fmt.Errorf("analyze: want one pattern, got %d files and pattern %q", len(files), *pattern)
+	}
+
+	var l *Life
+	var err error
+	switch {
+	case *pattern != "":
+		l, err = NewLifeFromPattern(*pattern, patternMargin)
+	case files[0] == "-":
+		l, err = NewLifeFromReader(os.Stdin)
+	case strings.HasPrefix(files[0], "http://") || strings.HasPrefix(files[0], "https://"):
+		l, err = NewLifeFromURL(files[0], FetchTimeout)
+	default:
+		l, err = NewLifeFromFile(files[0])
+	}
+	if err != nil {
+		return fmt.Errorf("analyze: %v", err)
+	}
+	if *ruleFlag != "" {
+		r, err := LookupRule(*ruleFlag)
+		if err != nil {
+			return fmt.Errorf("analyze: %v", err)
+		}
+		l.SetRule(r)
+	}
+	if err := l.SetGrowth(*grow, DefaultMaxCells); err != nil {
+		return fmt.Errorf("analyze: %v", err)
+	}
+
+	r := l.Analyze(*generations)
+	if *jsonFlag {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return r.Fprint(out)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []string
+		maxGen int
+		want   Report
+	}{
+		{"block", library["block"], 100, Report{
+			Generations: 1, InitialPopulation: 4, FinalPopulation: 4, PeakPopulation: 4,
+			BoundingBox: Box{Top: 2, Left: 2, Height: 2, Width: 2},
+			Stable:      true, StableGeneration: 0, Period: 1,
+		}},
+		{"blinker", library["blinker"], 100, Report{
+			Generations: 2, InitialPopulation: 3, FinalPopulation: 3, PeakPopulation: 3,
+			BoundingBox: Box{Top: 2, Left: 2, Height: 1, Width: 3},
+			Stable:      true, StableGeneration: 0, Period: 2,
+		}},
+		{"domino", []string{"oo"}, 100, Report{
+			Generations: 1, InitialPopulation: 2, FinalPopulation: 0, PeakPopulation: 2,
+			Stable: true, StableGeneration: 1, Period: 1, Extinct: true,
+		}},
+	}
+	for _, tt := range tests {
+		l, err := newLifeWithMargin(patternField(tt.rows), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := l.Analyze(tt.maxGen); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeNotStable(t *testing.T) {
+	l, err := NewLifeFromPattern("r-pentomino", 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := l.Analyze(50)
+	if got.Stable || got.Extinct || got.Generations != 50 || l.gen != 50 {
+		t.Errorf("got %+v at generation %d, want 50 generations without cycle", got, l.gen)
+	}
+	if got.PeakPopulation < got.InitialPopulation || got.InitialPopulation != 5 {
+		t.Errorf("got population %d, peak %d", got.InitialPopulation, got.PeakPopulation)
+	}
+}
+
+func TestAnalyzeMain(t *testing.T) {
+	tests := []struct {
+		args   []string
+		period int
+	}{
+		{[]string{"-json", "-pattern", "blinker"}, 2},
+		{[]string{"-json", "testdata/glider.cells", "-grow", "4", "-generations", "10"}, 0},
+		{[]string{"-pattern", "block", "-json"}, 1},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := analyzeMain(tt.args, &buf); err != nil {
+			t.Fatalf("%q: %v", tt.args, err)
+		}
+		var r Report
+		if err := json.Unmarshal(buf.Bytes(), &r); err != nil {
+			t.Fatalf("%q: %v\n%s", tt.args, err, buf.String())
+		}
+		if r.Period != tt.period {
+			t.Errorf("%q: period %d, want %d", tt.args, r.Period, tt.period)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := analyzeMain([]string{"-pattern", "blinker"}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "stable:       period 2 from generation 0") {
+		t.Errorf("report is\n%s", buf.String())
+	}
+}
+
+func TestAnalyzeMainError(t *testing.T) {
+	for _, args := range [][]string{
+		{"-no-such-flag"},
+		{"-generations", "many", "-pattern", "blinker"},
+		{},
+		{"a.rle", "b.rle"},
+		{"-pattern", "blinker", "a.rle"},
+		{"-pattern", "no-such-pattern"},
+		{"-pattern", "blinker", "-rule", "B9/S"},
+		{"-pattern", "blinker", "-grow", "-1"},
+	} {
+		err := analyzeMain(args, &bytes.Buffer{})
+		if err == nil || err == flag.ErrHelp {
+			t.Errorf("%q: got %v, want error", args, err)
+		}
+	}
+	if err := analyzeMain([]string{"-h"}, &bytes.Buffer{}); err != flag.ErrHelp {
+		t.Errorf("-h: got %v, want %v", err, flag.ErrHelp)
+	}
+}
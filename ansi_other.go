@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "io"
+
+// enableANSI reports whether ANSI escape sequences are available on w.
+// Terminals other than Windows console accept them.
+func enableANSI(w io.Writer) bool {
+	return true
+}
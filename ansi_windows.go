@@ -0,0 +1,35 @@
+//go:build windows
+
+package main
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// enableVirtualTerminalProcessing is the console mode to interpret ANSI
+// escape sequences, which is available on Windows 10 and later.
+const enableVirtualTerminalProcessing = 0x0004
+
+var setConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// enableANSI enables ANSI escape sequences on the console which w is
+// connected to, and reports whether they are available.
+func enableANSI(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return true
+	}
+	h := syscall.Handle(f.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		// not a console, e.g. redirected to a file.
+		return true
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	r, _, _ := setConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// apgDigits is the base-32 digits of extended Wechsler format. Each digit
+// encodes a column of 5 cells, the top cell at the least significant bit.
+const apgDigits = "0123456789abcdefghijklmnopqrstuv"
+
+// ParseApgcode returns a field holding the object of apgcode such as
+// "xq4_153" for a glider, sized to the bounding box of its live cells.
+// See details of the format.
+//
+//	http://www.conwaylife.com/wiki/Apgcode
+func ParseApgcode(code string) (*Field, error) {
+	i := strings.IndexByte(code, '_')
+	if i < 0 || !validApgPrefix(code[:i]) {
+		return nil, fmt.Errorf("apgcode %q: want prefix such as xs4_, xp2_ or xq4_", code)
+	}
+
+	var cells [][2]int // pairs of row and column
+	strip, col := 0, 0
+	for pos := i + 1; pos < len(code); pos++ {
+		c := code[pos]
+		switch {
+		case c == 'w':
+			col += 2
+		case c == 'x':
+			col += 3
+		case c == 'y':
+			pos++
+			if pos >= len(code) {
+				return nil, fmt.Errorf("apgcode %q: missing run length after 'y' at position %d", code, pos-1)
+			}
+			n := strings.IndexByte("0123456789abcdefghijklmnopqrstuvwxyz", code[pos])
+			if n < 0 {
+				return nil, fmt.Errorf("apgcode %q: invalid run length %q at position %d", code, code[pos], pos)
+			}
+			col += 4 + n
+		case c == 'z':
+			strip++
+			col = 0
+		default:
+			v := strings.IndexByte(apgDigits, c)
+			if v < 0 {
+				return nil, fmt.Errorf("apgcode %q: invalid character %q at position %d", code, c, pos)
+			}
+			for b := 0; b < 5; b++ {
+				if v&(1<<uint(b)) != 0 {
+					cells = append(cells, [2]int{strip*5 + b, col})
+				}
+			}
+			col++
+		}
+	}
+	if len(cells) == 0 {
+		return nil, fmt.Errorf("apgcode %q: no live cells", code)
+	}
+
+	minR, minC, maxR, maxC := cells[0][0], cells[0][1], cells[0][0], cells[0][1]
+	for _, c := range cells {
+		if c[0] < minR {
+			minR = c[0]
+		}
+		if c[0] > maxR {
+			maxR = c[0]
+		}
+		if c[1] < minC {
+			minC = c[1]
+		}
+		if c[1] > maxC {
+			maxC = c[1]
+		}
+	}
+	f := NewField(maxR-minR+1, maxC-minC+1)
+	for _, c := range cells {
+		f.put(c[0]-minR, c[1]-minC, true)
+	}
+	return f, nil
+}
+
+// validApgPrefix reports whether p is the prefix of apgcode such as "xs4".
+func validApgPrefix(p string) bool {
+	if len(p) < 3 || p[0] != 'x' || strings.IndexByte("spq", p[1]) < 0 {
+		return false
+	}
+	for _, c := range p[2:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
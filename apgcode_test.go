@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseApgcode(t *testing.T) {
+	tests := []struct {
+		code string
+		want []string
+	}{
+		{"xs4_33", []string{"oo", "oo"}},                               // block
+		{"xs6_696", []string{".o.", "o.o", "o.o", ".o."}},              // beehive
+		{"xs5_253", []string{".oo", "o.o", ".o."}},                     // boat
+		{"xs6_356", []string{"oo.", "o.o", ".oo"}},                     // ship
+		{"xs4_252", []string{".o.", "o.o", ".o."}},                     // tub
+		{"xs7_2596", []string{".oo.", "o..o", ".o.o", "..o."}},         // loaf
+		{"xs8_6996", []string{".oo.", "o..o", "o..o", ".oo."}},         // pond
+		{"xp2_7", []string{"o", "o", "o"}},                             // blinker
+		{"xp2_7e", []string{"o.", "oo", "oo", ".o"}},                   // toad
+		{"xp2_318c", []string{"oo..", "o...", "...o", "..oo"}},         // beacon
+		{"xq4_153", []string{"ooo", "..o", ".o."}},                     // glider
+		{"xq4_6frc", []string{".oo.", "ooo.", "oo.o", ".ooo", "..o."}}, // lightweight spaceship
+		{"xp15_4r4z4r4", []string{ // pentadecathlon
+			".o.", ".o.", "o.o", ".o.", ".o.",
+			".o.", ".o.", "o.o", ".o.", ".o.",
+		}},
+		{"xp2_7w7", []string{"o..o", "o..o", "o..o"}},
+		{"xp2_7x7", []string{"o...o", "o...o", "o...o"}},
+		{"xp2_7y07", []string{"o....o", "o....o", "o....o"}},
+		{"xp2_7ya7", []string{"o..............o", "o..............o", "o..............o"}},
+		{"xs2_0z3", []string{"o", "o"}},
+	}
+	for _, tt := range tests {
+		f, err := ParseApgcode(tt.code)
+		if err != nil {
+			t.Errorf("%s: %v", tt.code, err)
+			continue
+		}
+		if got := fieldRows(f); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.code, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestParseApgcodeError(t *testing.T) {
+	tests := []struct {
+		code string
+		want string // substring of the error
+	}{
+		{"", "prefix"},
+		{"33", "prefix"},
+		{"xs_33", "prefix"},
+		{"xa4_33", "prefix"},
+		{"xs4a_33", "prefix"},
+		{"xs4_", "no live cells"},
+		{"xs4_0w0", "no live cells"},
+		{"xs4_3!3", "position 5"},
+		{"xs4_33A", "position 6"},
+		{"xp2_7y", "position 5"},
+		{"xp2_7y!7", "position 6"},
+	}
+	for _, tt := range tests {
+		_, err := ParseApgcode(tt.code)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseApgcode(%q) = %v, want error containing %q", tt.code, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewLifeFromCells create new lifegame buffer from plaintext (.cells) pattern.
+// Lines starting with '!' are comments, '.' is dead cell and 'O' or 'o' is
+// live cell. Short rows are padded with dead cells to the longest row.
+// See details of the format.
+//
+//	http://www.conwaylife.com/wiki/Plaintext
+func NewLifeFromCells(r io.Reader) (*Life, error) {
+	s := bufio.NewScanner(r)
+	init := [][]bool{}
+	w := 0
+	lineno := 0
+	for s.Scan() {
+		lineno++
+		line := strings.TrimRight(s.Text(), "\r")
+		if strings.HasPrefix(line, "!") {
+			continue
+		}
+		row := make([]bool, len(line))
+		for i, c := range line {
+			switch c {
+			case '.':
+			case 'O', 'o':
+				row[i] = true
+			default:
+				return nil, fmt.Errorf("cells: line %d: unexpected character %q", lineno, c)
+			}
+		}
+		if len(row) > w {
+			w = len(row)
+		}
+		init = append(init, row)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	// trailing blank lines are not a part of the pattern.
+	for len(init) > 0 && len(init[len(init)-1]) == 0 {
+		init = init[:len(init)-1]
+	}
+	if len(init) == 0 || w == 0 {
+		return nil, errors.New("cells: no pattern found")
+	}
+	for i, row := range init {
+		if len(row) < w {
+			init[i] = append(row, make([]bool, w-len(row))...)
+		}
+	}
+	return NewLife(len(init), w, init)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Checkpoint writes the state of the lifegame including generation counter
+// to w with encoding/gob, so that it can be resumed by LoadLife. The state
+// is encoded by Life.GobEncode.
+func (l *Life) Checkpoint(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(l)
+}
+
+// LoadLife reads the state written by Checkpoint and returns the lifegame
+// resumed from it.
+func LoadLife(r io.Reader) (*Life, error) {
+	l := new(Life)
+	if err := gob.NewDecoder(r).Decode(l); err != nil {
+		return nil, fmt.Errorf("checkpoint: %v", err)
+	}
+	return l, nil
+}
+
+// saveCheckpoint writes checkpoint of l to path atomically, by writing to
+// temporary file in the same directory and renaming it.
+func saveCheckpoint(path string, l *Life) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if err := l.Checkpoint(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadCheckpoint reads checkpoint from the file of path.
+func loadCheckpoint(path string) (*Life, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadLife(file)
+}
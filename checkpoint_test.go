@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	for _, rule := range []string{"B3/S23", "B36/S23", "B2/S/C3", "B2/S345/C4"} {
+		r, err := ParseRule(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := NewRandomLife(20, 30, 0.3, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.SetRule(r)
+		l.SetTopology(Mirror)
+		l.Run(5)
+
+		path := filepath.Join(t.TempDir(), "life.gob")
+		if err := saveCheckpoint(path, l); err != nil {
+			t.Fatalf("%v: saveCheckpoint: %v", rule, err)
+		}
+		got, err := loadCheckpoint(path)
+		if err != nil {
+			t.Fatalf("%v: loadCheckpoint: %v", rule, err)
+		}
+		if got.gen != l.gen || got.cur.topo != l.cur.topo || got.Rule() != l.Rule() {
+			t.Fatalf("%v: restored generation %d, topology %v, rule %v, want %d, %v, %v",
+				rule, got.gen, got.cur.topo, got.Rule(), l.gen, l.cur.topo, l.Rule())
+		}
+		for g := 0; g <= 10; g++ {
+			if !equalStates(got.cur, l.cur) {
+				t.Fatalf("%v: generation %d differs after restore", rule, l.gen)
+			}
+			l.Next()
+			got.Next()
+		}
+	}
+}
+
+func TestLoadLifeUnknownTopology(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(1, 2)
+	l.cur.topo = Topology(99)
+	if err := l.Checkpoint(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLife(&buf); err == nil {
+		t.Error("LoadLife with unknown topology succeeded")
+	}
+}
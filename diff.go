@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"text/template"
+	"unicode/utf8"
+)
+
+// diffRedrawRatio is the inverse of the fraction of changed characters above
+// which DiffRenderer redraws the whole screen, as positioning the cursor for
+// each change costs more than that.
+const diffRedrawRatio = 4
+
+// DiffRenderer redraws only characters of the terminal which changed from
+// the previous generation. It redraws the whole screen for the first frame,
+// when the size of frames changes, when many characters changed, and after
+// Reset is called.
+// Characters are compared with their colors, so that changes of colors are
+// redrawn in Color render mode.
+type DiffRenderer struct {
+	Glyphs Glyphs             // characters of cells, which must be valid
+	Header *template.Template // header line, or nil for no header
+	Border bool               // whether to draw a border around the field
+	w      io.Writer
+	mode   RenderMode
+	prev   [][]termCell // lines of the previous frame
+	reset  int32        // set by Reset to redraw the whole screen
+}
+
+// NewDiffRenderer returns a renderer redrawing changes of the terminal which
+// w is connected to in render mode m.
+func NewDiffRenderer(w io.Writer, m RenderMode) *DiffRenderer {
+	return &DiffRenderer{Glyphs: DefaultGlyphs, Header: DefaultHeader, w: w, mode: m}
+}
+
+// Reset makes the next Render redraw the whole screen, e.g. after the
+// terminal is resized. It is safe to call Reset while Render is running.
+func (r *DiffRenderer) Reset() {
+	atomic.StoreInt32(&r.reset, 1)
+}
+
+// Render redraws the changes of the terminal with the field of generation gen.
+func (r *DiffRenderer) Render(f *Field, gen int) error {
+	var buf bytes.Buffer
+	if err := renderGeneration(&buf, f, gen, r.mode, r.Glyphs, r.Header, r.Border); err != nil {
+		return err
+	}
+	return r.renderText(buf.Bytes())
+}
+
+// renderText redraws the changes of the terminal with text.
+func (r *DiffRenderer) renderText(text []byte) error {
+	if !enableANSI(r.w) {
+		r.prev = nil
+		return redraw(r.w, text)
+	}
+	lines := splitLines(text)
+
+	full := atomic.SwapInt32(&r.reset, 0) != 0 || len(lines) != len(r.prev)
+	var out bytes.Buffer
+	if !full {
+		changed, total := 0, 0
+		for i, line := range lines {
+			prev := r.prev[i]
+			if len(line) != len(prev) {
+				full = true
+				break
+			}
+			total += len(line)
+			col := 1 // terminal column of line[j], as wide characters take two
+			for j := 0; j < len(line); {
+				if line[j] == prev[j] {
+					col += cellWidth(line[j])
+					j++
+					continue
+				}
+				k := j
+				fmt.Fprintf(&out, "\x1b[%d;%dH", i+1, col)
+				for k < len(line) && line[k] != prev[k] {
+					col += cellWidth(line[k])
+					k++
+				}
+				writeCells(&out, line[j:k])
+				changed += k - j
+				j = k
+			}
+		}
+		if changed*diffRedrawRatio > total {
+			full = true
+		}
+	}
+	r.prev = lines
+	if full {
+		_, err := r.w.Write(redrawFrame(text))
+		return err
+	}
+	if out.Len() == 0 {
+		return nil
+	}
+	// leave the cursor below the frame as full redraw does.
+	fmt.Fprintf(&out, "\x1b[%d;1H", len(lines)+1)
+	_, err := r.w.Write(out.Bytes())
+	return err
+}
+
+// termCell is a character of the terminal with SGR escape sequences coloring
+// it, or "" for the default color.
+type termCell struct {
+	ch  rune
+	sgr string
+}
+
+// cellWidth returns the number of terminal columns which c takes.
+func cellWidth(c termCell) int {
+	if isWide(c.ch) {
+		return 2
+	}
+	return 1
+}
+
+// writeCells appends cells to buf with their colors, and resets the color
+// at the end.
+func writeCells(buf *bytes.Buffer, cells []termCell) {
+	sgr := ""
+	for _, c := range cells {
+		if c.sgr != sgr {
+			if sgr != "" {
+				buf.WriteString(ansiReset)
+			}
+			buf.WriteString(c.sgr)
+			sgr = c.sgr
+		}
+		buf.WriteRune(c.ch)
+	}
+	if sgr != "" {
+		buf.WriteString(ansiReset)
+	}
+}
+
+// splitLines returns lines of text as characters with their colors, without
+// newlines. SGR escape sequences are kept as the color of the characters
+// following them until the color is reset, and other escape sequences are
+// dropped.
+func splitLines(text []byte) [][]termCell {
+	text = bytes.TrimSuffix(text, []byte("\n"))
+	parts := bytes.Split(text, []byte("\n"))
+	lines := make([][]termCell, len(parts))
+	sgr := ""
+	for i, p := range parts {
+		line := make([]termCell, 0, utf8.RuneCount(p))
+		for len(p) > 0 {
+			if n := csiLen(p); n > 0 {
+				switch seq := string(p[:n]); {
+				case p[n-1] != 'm':
+				case seq == ansiReset || seq == "\x1b[m":
+					sgr = ""
+				default:
+					sgr += seq
+				}
+				p = p[n:]
+				continue
+			}
+			c, n := utf8.DecodeRune(p)
+			line = append(line, termCell{c, sgr})
+			p = p[n:]
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// csiLen returns the length of the control sequence at the start of p, which
+// ends with a byte from @ to ~, or 0 if p does not start with one.
+func csiLen(p []byte) int {
+	if len(p) < 2 || p[0] != '\x1b' || p[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(p); i++ {
+		if p[i] >= 0x40 && p[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
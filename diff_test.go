@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+// countingWriter counts bytes written to it.
+type countingWriter struct {
+	n int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+// screen emulates the terminal for escape sequences written by renderers.
+type screen struct {
+	lines    [][]termCell
+	row, col int
+	sgr      string
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if k := csiLen(p); k > 0 {
+			seq := string(p[:k])
+			switch p[k-1] {
+			case 'H':
+				s.row, s.col = 0, 0
+				if k > 3 {
+					fmt.Sscanf(seq, "\x1b[%d;%dH", &s.row, &s.col)
+					s.row, s.col = s.row-1, s.col-1
+				}
+			case 'K':
+				s.grow()
+				s.lines[s.row] = s.lines[s.row][:s.col]
+			case 'J':
+				s.grow()
+				s.lines[s.row] = s.lines[s.row][:s.col]
+				s.lines = s.lines[:s.row+1]
+			case 'm':
+				if seq == ansiReset {
+					s.sgr = ""
+				} else {
+					s.sgr += seq
+				}
+			}
+			p = p[k:]
+			continue
+		}
+		c, k := utf8.DecodeRune(p)
+		p = p[k:]
+		if c == '\n' {
+			s.row, s.col = s.row+1, 0
+			continue
+		}
+		s.grow()
+		for len(s.lines[s.row]) <= s.col {
+			s.lines[s.row] = append(s.lines[s.row], termCell{' ', ""})
+		}
+		s.lines[s.row][s.col] = termCell{c, s.sgr}
+		s.col++
+	}
+	return n, nil
+}
+
+// grow adds lines to the screen up to the cursor.
+func (s *screen) grow() {
+	for len(s.lines) <= s.row {
+		s.lines = append(s.lines, nil)
+	}
+}
+
+func TestDiffRenderer(t *testing.T) {
+	for _, m := range []RenderMode{ASCII, Color, HalfBlock} {
+		l, err := NewRandomLife(20, 60, 0.3, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.SetRenderMode(m)
+		l.Run(200)
+		var got screen
+		r := NewDiffRenderer(&got, m)
+		diffs := 0
+		for g := 0; g < 50; g++ {
+			var buf bytes.Buffer
+			r.w = &buf
+			if err := r.Render(l.cur, l.gen); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(buf.Bytes(), []byte(ansiHome)) {
+				diffs++
+			}
+			got.Write(buf.Bytes())
+			var want screen
+			if err := NewTerminalRenderer(&want, m).Render(l.cur, l.gen); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.lines, want.lines) {
+				t.Fatalf("%v: generation %d: screen differs from full redraw", m, l.gen)
+			}
+			l.Next()
+		}
+		if diffs == 0 {
+			t.Errorf("%v: every frame was redrawn in full", m)
+		}
+	}
+}
+
+// benchmarkRender renders generations of a mostly stable 60x200 soup in
+// render mode m by r, and reports the number of bytes written per frame.
+func benchmarkRender(b *testing.B, m RenderMode, newRenderer func(w *countingWriter, m RenderMode) Renderer) {
+	l, err := NewRandomLife(60, 200, 0.3, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	l.SetRenderMode(m)
+	l.Run(1000)
+	var w countingWriter
+	r := newRenderer(&w, m)
+	r.Render(l.cur, l.gen)
+	w.n = 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Next()
+		if err := r.Render(l.cur, l.gen); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(w.n)/float64(b.N), "bytes/frame")
+}
+
+func BenchmarkTerminalRenderer(b *testing.B) {
+	benchmarkRender(b, ASCII, func(w *countingWriter, m RenderMode) Renderer { return NewTerminalRenderer(w, m) })
+}
+
+func BenchmarkDiffRenderer(b *testing.B) {
+	benchmarkRender(b, ASCII, func(w *countingWriter, m RenderMode) Renderer { return NewDiffRenderer(w, m) })
+}
+
+func BenchmarkTerminalRendererColor(b *testing.B) {
+	benchmarkRender(b, Color, func(w *countingWriter, m RenderMode) Renderer { return NewTerminalRenderer(w, m) })
+}
+
+func BenchmarkDiffRendererColor(b *testing.B) {
+	benchmarkRender(b, Color, func(w *countingWriter, m RenderMode) Renderer { return NewDiffRenderer(w, m) })
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// NewElementaryLife create new lifegame buffer of h x w cells running the
+// elementary cellular automaton of Wolfram's rule number. The top row is the
+// tape starting with a live cell at the center, each generation appends the
+// next tape below, and rows scroll upward when the field is filled.
+func NewElementaryLife(h, w, rule int) (*Life, error) {
+	if rule < 0 || rule > 255 {
+		return nil, fmt.Errorf("elementary rule %d is out of range [0, 255]", rule)
+	}
+	if h <= 0 || w <= 0 {
+		return nil, errors.New("elementary: field must have cells")
+	}
+	cur := NewField(h, w)
+	cur.rule = Rule{elementary: true, wolfram: uint8(rule)}
+	cur.put(0, w/2, true)
+	return &Life{cur: cur, next: cur.blank()}, nil
+}
+
+// parseWolfram parses rule number of elementary cellular automata such as
+// "W110".
+func parseWolfram(s string) (Rule, error) {
+	n, err := strconv.Atoi(s[1:])
+	if err != nil || n < 0 || n > 255 {
+		return Rule{}, fmt.Errorf("rule %q: want W0 to W255", s)
+	}
+	return Rule{elementary: true, wolfram: uint8(n)}, nil
+}
+
+// elementaryNext returns whether the cell of c in the tape next to row r is
+// alive. Cells beyond the ends of the row follow the topology of the field.
+func (f *Field) elementaryNext(r, c int) bool {
+	k := 0
+	for j := c - 1; j <= c+1; j++ {
+		k <<= 1
+		if f.Alive(r, j) {
+			k |= 1
+		}
+	}
+	return f.rule.wolfram&(1<<uint(k)) != 0
+}
+
+// tape returns the row of current tape of elementary cellular automata.
+func (l *Life) tape() int {
+	if l.gen < l.cur.h-1 {
+		return l.gen
+	}
+	return l.cur.h - 1
+}
+
+// nextElementaryRows calculates rows from lo to hi-1 of next generation of
+// elementary cellular automata, scrolling the rows when the field is full.
+func (l *Life) nextElementaryRows(lo, hi int) {
+	t := l.tape()
+	full := t == l.cur.h-1
+	for i := lo; i < hi; i++ {
+		// rows above the next tape are copied, shifted up when full.
+		switch {
+		case full && i < t, !full && i <= t:
+			src := i
+			if full {
+				src = i + 1
+			}
+			for j := 0; j < l.cur.w; j++ {
+				l.next.put(i, j, l.cur.get(src, j))
+			}
+		case i == t, i == t+1:
+			for j := 0; j < l.cur.w; j++ {
+				l.next.put(i, j, l.cur.elementaryNext(t, j))
+			}
+		default:
+			for j := 0; j < l.cur.w; j++ {
+				l.next.put(i, j, false)
+			}
+		}
+		// ages and activity are not tracked by elementary rules, but next
+		// may hold those of an older generation.
+		for j := 0; j < l.cur.w; j++ {
+			if l.next.ages != nil {
+				l.next.ages[i][j] = 0
+			}
+			if l.next.heat != nil {
+				l.next.heat[i][j] = 0
+			}
+		}
+	}
+}
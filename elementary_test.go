@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewElementaryLife(t *testing.T) {
+	tests := []struct {
+		rule   int
+		golden []string // tapes of each generation on the torus
+	}{
+		{30, []string{
+			".......o.......",
+			"......ooo......",
+			".....oo..o.....",
+			"....oo.oooo....",
+			"...oo..o...o...",
+			"..oo.oooo.ooo..",
+			".oo..o....o..o.",
+			"oo.oooo..oooooo",
+			"...o...ooo.....",
+			"..ooo.oo..o....",
+			".oo...o.oooo...",
+		}},
+		{110, []string{
+			".......o.......",
+			"......oo.......",
+			".....ooo.......",
+			"....oo.o.......",
+			"...ooooo.......",
+			"..oo...o.......",
+			".ooo..oo.......",
+			"oo.o.ooo.......",
+			"oooooo.o......o",
+			".....ooo.....oo",
+			"....oo.o....ooo",
+		}},
+	}
+	const h = 8
+	for _, tt := range tests {
+		l, err := NewElementaryLife(h, 15, tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for g := range tt.golden {
+			// rows scroll upward once the field is filled.
+			top := 0
+			if g >= h {
+				top = g - h + 1
+			}
+			want := make([]string, h)
+			for i := range want {
+				if top+i <= g {
+					want[i] = tt.golden[top+i]
+				} else {
+					want[i] = strings.Repeat(".", 15)
+				}
+			}
+			if got := fieldRows(l.cur); !reflect.DeepEqual(got, want) {
+				t.Fatalf("rule %d generation %d: got\n%s\nwant\n%s",
+					tt.rule, g, strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
+			l.Next()
+		}
+	}
+}
+
+func TestNewElementaryLifeError(t *testing.T) {
+	for _, args := range [][3]int{{8, 15, -1}, {8, 15, 256}, {0, 15, 30}, {8, 0, 30}} {
+		if _, err := NewElementaryLife(args[0], args[1], args[2]); err == nil {
+			t.Errorf("NewElementaryLife%v succeeded", args)
+		}
+	}
+	for _, rule := range []string{"W", "W256", "W-1", "Wx"} {
+		if _, err := ParseRule(rule); err == nil {
+			t.Errorf("ParseRule(%q) succeeded", rule)
+		}
+	}
+	rule, err := ParseRule("W110")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []Neighborhood{Moore, VonNeumann, Hexagonal} {
+		if _, err := rule.WithNeighborhood(n); err == nil {
+			t.Errorf("WithNeighborhood(%v) of W110 succeeded", n)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// maxPatternSize is the maximum size of decompressed pattern, which keeps
+// malicious gzip stream from exhausting memory.
+const maxPatternSize = 64 << 20
+
+// maxPatternCells is the maximum number of cells of the field declared by
+// pattern, the same as 4096 x 4096 cells of macrocell pattern, which keeps
+// a header declaring a huge field from exhausting memory.
+const maxPatternCells = 1 << (2 * maxMacrocellLevel)
+
+var errPatternTooLarge = errors.New("pattern is too large")
+
+// formatFromExt returns the parser for the pattern format indicated by the
+// extension of name, or nil if it is unknown. ".gz" suffix is ignored.
+func formatFromExt(name string) func(io.Reader) (*Life, error) {
+	if strings.ToLower(filepath.Ext(name)) == ".gz" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".rle":
+		return NewLifeFromRLE
+	case ".cells":
+		return NewLifeFromCells
+	case ".lif", ".life":
+		return newLifeFromLif
+	case ".mc":
+		return NewLifeFromMacrocell
+	}
+	return nil
+}
+
+// readPattern parses pattern read from br with parse, or with the parser
+// detected by sniffFormat if parse is nil. Gzip compressed pattern is
+// decompressed transparently.
+func readPattern(br *bufio.Reader, parse func(io.Reader) (*Life, error)) (*Life, error) {
+	var lr *limitReader
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		lr = &limitReader{r: zr, n: maxPatternSize}
+		br = bufio.NewReader(lr)
+	}
+	if parse == nil {
+		parse = sniffFormat(br)
+	}
+	l, err := parse(br)
+	// parsers may stop at the broken part of the stream without noticing it.
+	if lr != nil && lr.err != nil {
+		return nil, lr.err
+	}
+	return l, err
+}
+
+// limitReader reads from r at most n bytes, and fails if r has more.
+// The first error other than io.EOF is kept in err.
+type limitReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (l *limitReader) Read(p []byte) (n int, err error) {
+	defer func() {
+		if err != nil && err != io.EOF && l.err == nil {
+			l.err = err
+		}
+	}()
+	if l.n <= 0 {
+		var b [1]byte
+		if n, err := l.r.Read(b[:]); n == 0 && err != nil {
+			return 0, err
+		}
+		return 0, errPatternTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err = l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// sniffLen is the number of bytes examined to detect the pattern format.
+const sniffLen = 4096
+
+// utf8BOM is the byte order mark which some editors write at the beginning
+// of UTF-8 files.
+const utf8BOM = "\ufeff"
+
+// sniffFormat peeks the beginning of br and returns the parser for the
+// pattern format found there.
+func sniffFormat(br *bufio.Reader) func(io.Reader) (*Life, error) {
+	head, _ := br.Peek(sniffLen)
+	for len(head) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(head, '\n'); i >= 0 {
+			line, head = head[:i], head[i+1:]
+		} else {
+			line, head = head, nil
+		}
+		line = bytes.TrimSpace(bytes.TrimPrefix(line, []byte(utf8BOM)))
+		switch {
+		case len(line) == 0:
+			continue
+		case bytes.HasPrefix(line, []byte(life106Header)):
+			return NewLifeFromLife106
+		case bytes.HasPrefix(line, []byte(life105Header)):
+			return NewLifeFromLife105
+		case bytes.HasPrefix(line, []byte(macrocellHeader)):
+			return NewLifeFromMacrocell
+		case line[0] == '!':
+			return NewLifeFromCells
+		case line[0] == '#':
+			// comment lines of RLE pattern.
+			continue
+		case line[0] == 'x' && bytes.HasPrefix(bytes.TrimSpace(line[1:]), []byte("=")):
+			return NewLifeFromRLE
+		}
+		break
+	}
+	return newLifeFromText
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewLifeFromFileGzip(t *testing.T) {
+	for _, name := range []string{"gosperglidergun.rle", "glider.cells", "glider.lif"} {
+		path := filepath.Join("testdata", name)
+		plain, err := NewLifeFromFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := NewLifeFromFile(path + ".gz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !gz.cur.Equal(plain.cur) || gz.Rule() != plain.Rule() {
+			t.Errorf("%s.gz differs from %s", name, name)
+		}
+		if plain.Population() == 0 {
+			t.Errorf("%s: no live cells", name)
+		}
+	}
+}
+
+func TestNewLifeFromFileCorruptGzip(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "gosperglidergun.rle.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, corrupt := range map[string][]byte{
+		"truncated.rle.gz": data[:len(data)/2],
+		"header.rle.gz":    append([]byte{0x1f, 0x8b, 0}, data[3:]...),
+		"checksum.rle.gz":  append(append([]byte{}, data[:len(data)-8]...), 0, 0, 0, 0, 0, 0, 0, 0),
+	} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := ioutil.WriteFile(path, corrupt, 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := NewLifeFromFile(path)
+		if err == nil {
+			t.Errorf("%s: NewLifeFromFile succeeded", name)
+		} else if !strings.Contains(err.Error(), path) {
+			t.Errorf("%s: error %q does not name the file", name, err)
+		}
+	}
+}
+
+func TestNewLifeFromReaderGzipTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	line := bytes.Repeat([]byte(" "), 1<<20)
+	line[len(line)-1] = '\n'
+	for n := 0; n <= maxPatternSize; n += len(line) {
+		zw.Write(line)
+	}
+	zw.Close()
+	if _, err := NewLifeFromReader(&buf); err != errPatternTooLarge {
+		t.Errorf("NewLifeFromReader = %v, want %v", err, errPatternTooLarge)
+	}
+}
+
+func TestNewLifeFromCells(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"!Name: glider\n.O\n..O\nOOO\n", []string{".o.", "..o", "ooo"}},
+		// comments may appear between rows, and are not rows.
+		{".O\n!comment\n..O\n! another\nOOO", []string{".o.", "..o", "ooo"}},
+		{"o.\r\n\r\n.o\r\n\r\n\r\n", []string{"o.", "..", ".o"}},
+	}
+	for _, tt := range tests {
+		l, err := NewLifeFromCells(strings.NewReader(tt.text))
+		if err != nil {
+			t.Fatalf("%q: %v", tt.text, err)
+		}
+		if got := fieldRows(l.cur); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNewLifeFromCellsError(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // substring of the error
+	}{
+		{".O\n.X\n", `line 2: unexpected character 'X'`},
+		{"!c\n.O\n!c\n.*.\n", `line 4: unexpected character '*'`},
+		{"O O\n", `line 1: unexpected character ' '`},
+		{"O\tO\n", `line 1: unexpected character '\t'`},
+		{"O!O\n", `line 1: unexpected character '!'`},
+		{"..\n.ö\n", `line 2: unexpected character 'ö'`},
+		{"", "no pattern found"},
+		{"!Name: empty\n\n", "no pattern found"},
+	}
+	for _, tt := range tests {
+		_, err := NewLifeFromCells(strings.NewReader(tt.text))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got error %v, want error containing %q", tt.text, err, tt.want)
+		}
+	}
+}
+
+func TestNewLifeFromStdin(t *testing.T) {
+	dir := t.TempDir()
+	texts := map[string]string{
+		"lf.txt":   ".o.\n..o\nooo\n",
+		"crlf.txt": ".o.\r\n..o\r\nooo\r\n",
+		"eof.txt":  ".o.\n..o\nooo",
+	}
+	var paths []string
+	for name, text := range texts {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	names, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, names...)
+	for _, path := range paths {
+		want, err := NewLifeFromFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// -file - reads a pipe such as "cat glider.cells | lifegame -file -",
+		// which is also read without -file as it is not a terminal.
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			w.Write(data)
+			w.Close()
+		}()
+		if isTerminal(r) {
+			t.Errorf("%s: pipe is a terminal", path)
+		}
+		got, err := NewLifeFromReader(r)
+		r.Close()
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if !got.cur.Equal(want.cur) || got.Rule() != want.Rule() {
+			t.Errorf("%s: pattern read from pipe differs from file", path)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import "bytes"
+
+// States returns the number of states of cells, which is 2 for rules other
+// than Generations rules.
+func (r Rule) States() int {
+	if r.states == 0 {
+		return 2
+	}
+	return r.states
+}
+
+// newDying returns dying states of h x w cells, all zero.
+func newDying(h, w int) [][]uint8 {
+	buf := make([]uint8, h*w)
+	dying := make([][]uint8, h)
+	for i := range dying {
+		dying[i] = buf[i*w : (i+1)*w]
+	}
+	return dying
+}
+
+// State returns the state of the cell of r & c: 0 for dead cells, 1 for
+// live cells, and 2 or more for dying cells of Generations rules, which
+// count up each generation until the number of states of the rule.
+func (f *Field) State(r, c int) int {
+	if f.get(r, c) {
+		return 1
+	}
+	if f.dying == nil || f.dying[r][c] == 0 {
+		return 0
+	}
+	return int(f.dying[r][c]) + 1
+}
+
+// trackDying starts or stops tracking dying cells as the rule needs.
+// All cells are treated as not dying when it starts.
+func (l *Life) trackDying() {
+	if l.cur.rule.states <= 2 {
+		l.cur.dying, l.next.dying = nil, nil
+		return
+	}
+	if l.cur.dying == nil {
+		l.cur.dying = newDying(l.cur.h, l.cur.w)
+		l.next.dying = newDying(l.next.h, l.next.w)
+	}
+}
+
+// nextDying returns the dying state of next generation of the cell of r &
+// c, which is alive in next generation when alive is true.
+func (f *Field) nextDying(r, c int, alive bool) uint8 {
+	switch d := f.dying[r][c]; {
+	case f.get(r, c) && !alive:
+		return 1
+	case d > 0 && int(d)+2 < f.rule.states:
+		return d + 1
+	}
+	return 0
+}
+
+// dyingRamp is the characters of dying cells of Generations rules, from
+// just died to almost dead.
+const dyingRamp = "%#*+=-:."
+
+// renderGenerations is like renderText but displays dying cells with
+// characters of dyingRamp by their states.
+func (f *Field) renderGenerations(buf *bytes.Buffer, g Glyphs) {
+	dying := f.rule.States() - 2
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			switch d := int(f.dying[i][j]); {
+			case f.get(i, j):
+				buf.WriteRune(g.Alive)
+			case d > 0:
+				buf.WriteByte(dyingRamp[(d-1)*len(dyingRamp)/dying])
+			default:
+				buf.WriteRune(g.Dead)
+			}
+		}
+		buf.WriteByte('\n')
+	}
+}
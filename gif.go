@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"compress/lzw"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+)
+
+// gifEncoder writes animated GIF frame by frame, so that long runs can be
+// encoded without holding all frames in memory.
+// See details of the format.
+//
+//	https://www.w3.org/Graphics/GIF/spec-gif89a.txt
+type gifEncoder struct {
+	w      *bufio.Writer
+	width  int
+	height int
+	depth  uint // log2 of the size of the color table
+	err    error
+}
+
+// newGIFEncoder writes the header of width x height GIF which loops forever
+// and uses p as its global color table.
+func newGIFEncoder(w io.Writer, width, height int, p color.Palette) (*gifEncoder, error) {
+	if width <= 0 || height <= 0 || width > 0xffff || height > 0xffff {
+		return nil, fmt.Errorf("gif: invalid image size %dx%d", width, height)
+	}
+	if len(p) == 0 || len(p) > 256 {
+		return nil, fmt.Errorf("gif: invalid palette of %d colors", len(p))
+	}
+	e := &gifEncoder{w: bufio.NewWriter(w), width: width, height: height, depth: 1}
+	for 1<<e.depth < len(p) {
+		e.depth++
+	}
+
+	e.w.WriteString("GIF89a")
+	e.writeUint16(width)
+	e.writeUint16(height)
+	e.w.Write([]byte{0x80 | byte(e.depth-1)<<4 | byte(e.depth-1), 0, 0})
+	for i := 0; i < 1<<e.depth; i++ {
+		c := color.RGBA{}
+		if i < len(p) {
+			c = color.RGBAModel.Convert(p[i]).(color.RGBA)
+		}
+		e.w.Write([]byte{c.R, c.G, c.B})
+	}
+	// NETSCAPE2.0 application extension with loop count 0, i.e. forever.
+	e.w.Write([]byte{0x21, 0xff, 11})
+	e.w.WriteString("NETSCAPE2.0")
+	e.w.Write([]byte{3, 1, 0, 0, 0})
+	return e, e.flushErr()
+}
+
+func (e *gifEncoder) writeUint16(n int) {
+	e.w.Write([]byte{byte(n), byte(n >> 8)})
+}
+
+// flushErr returns the first error while writing.
+func (e *gifEncoder) flushErr() error {
+	if e.err == nil && e.w.Buffered() >= 4096 {
+		e.err = e.w.Flush()
+	}
+	return e.err
+}
+
+// Frame writes img as the next frame shown for delay 100ths of a second.
+// img must have the size of the GIF and use the colors of its palette.
+func (e *gifEncoder) Frame(img *image.Paletted, delay int) error {
+	if e.err != nil {
+		return e.err
+	}
+	b := img.Bounds()
+	if b.Dx() != e.width || b.Dy() != e.height {
+		return fmt.Errorf("gif: frame of %dx%d differs from image size %dx%d", b.Dx(), b.Dy(), e.width, e.height)
+	}
+	if delay < 0 || delay > 0xffff {
+		return fmt.Errorf("gif: invalid delay %d", delay)
+	}
+
+	// graphic control extension and image descriptor
+	e.w.Write([]byte{0x21, 0xf9, 4, 0})
+	e.writeUint16(delay)
+	e.w.Write([]byte{0, 0, 0x2c, 0, 0, 0, 0})
+	e.writeUint16(e.width)
+	e.writeUint16(e.height)
+	e.w.WriteByte(0)
+
+	litWidth := int(e.depth)
+	if litWidth < 2 {
+		litWidth = 2
+	}
+	e.w.WriteByte(byte(litWidth))
+	bw := &gifBlockWriter{w: e.w}
+	lw := lzw.NewWriter(bw, lzw.LSB, litWidth)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := img.PixOffset(b.Min.X, y)
+		if _, err := lw.Write(img.Pix[i : i+e.width]); err != nil {
+			e.err = err
+			return err
+		}
+	}
+	if err := lw.Close(); err != nil {
+		e.err = err
+		return err
+	}
+	bw.close()
+	return e.flushErr()
+}
+
+// Close writes the trailer of GIF.
+func (e *gifEncoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.w.WriteByte(0x3b)
+	e.err = e.w.Flush()
+	return e.err
+}
+
+// gifBlockWriter splits image data into sub-blocks of at most 255 bytes.
+type gifBlockWriter struct {
+	w   *bufio.Writer
+	buf [255]byte
+	n   int
+}
+
+func (b *gifBlockWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		b.buf[b.n] = c
+		b.n++
+		if b.n == len(b.buf) {
+			b.flush()
+		}
+	}
+	return len(p), nil
+}
+
+func (b *gifBlockWriter) flush() {
+	if b.n == 0 {
+		return
+	}
+	b.w.WriteByte(byte(b.n))
+	b.w.Write(b.buf[:b.n])
+	b.n = 0
+}
+
+// close writes the remaining data and the block terminator.
+func (b *gifBlockWriter) close() {
+	b.flush()
+	b.w.WriteByte(0)
+}
+
+// WriteGIF steps the simulation generations times and writes each generation
+// as a frame of animated GIF. delay is the time between frames in 100ths of
+// a second.
+func (l *Life) WriteGIF(w io.Writer, generations, cellSize, delay int) error {
+	return l.WriteGIFEvery(w, generations, 1, cellSize, delay)
+}
+
+// WriteGIFEvery is like WriteGIF but writes a frame only every n generations.
+// Frames are encoded one by one, so memory use does not grow with generations.
+// Frames keep the area of the first generation when the field grows.
+func (l *Life) WriteGIFEvery(w io.Writer, generations, n, cellSize, delay int) error {
+	if generations <= 0 {
+		return errors.New("generations must be positive")
+	}
+	if n <= 0 {
+		return errors.New("frame interval must be positive")
+	}
+	img, err := l.cur.image(cellSize, false)
+	if err != nil {
+		return err
+	}
+	e, err := newGIFEncoder(w, img.Bounds().Dx(), img.Bounds().Dy(), palette)
+	if err != nil {
+		return err
+	}
+	top, left := l.cur.Origin()
+	height, width := l.cur.h, l.cur.w
+	for i := 0; i < generations; i++ {
+		if i%n == 0 {
+			l.cur.planeWindow(top, left, height, width).draw(img, cellSize)
+			if err := e.Frame(img, delay); err != nil {
+				return err
+			}
+		}
+		l.Next()
+	}
+	return e.Close()
+}
+
+// saveGIF writes animated GIF of the run to the file of path.
+func saveGIF(path string, l *Life, generations, n, cellSize, delay int) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := l.WriteGIFEvery(file, generations, n, cellSize, delay); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
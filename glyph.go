@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Glyphs are the characters to display live and dead cells in ASCII and
+// Color render modes.
+type Glyphs struct {
+	Alive, Dead rune
+}
+
+// DefaultGlyphs is the glyphs used unless specified.
+var DefaultGlyphs = Glyphs{Alive: 'o', Dead: ' '}
+
+// Validate reports an error unless both glyphs are printable characters of
+// single column width.
+func (g Glyphs) Validate() error {
+	for _, r := range []rune{g.Alive, g.Dead} {
+		if !unicode.IsGraphic(r) || unicode.Is(unicode.Mn, r) {
+			return fmt.Errorf("glyph %q is not printable", r)
+		}
+		if isWide(r) {
+			return fmt.Errorf("glyph %q is wider than a column", r)
+		}
+	}
+	return nil
+}
+
+// wideRanges is the ranges of East Asian wide and fullwidth characters and
+// emoji, which take two columns in terminals.
+var wideRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115f, 1},
+		{0x2e80, 0x303e, 1},
+		{0x3041, 0x33ff, 1},
+		{0x3400, 0x4dbf, 1},
+		{0x4e00, 0x9fff, 1},
+		{0xa000, 0xa4cf, 1},
+		{0xac00, 0xd7a3, 1},
+		{0xf900, 0xfaff, 1},
+		{0xfe30, 0xfe4f, 1},
+		{0xff00, 0xff60, 1},
+		{0xffe0, 0xffe6, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1f300, 0x1f64f, 1},
+		{0x1f900, 0x1f9ff, 1},
+		{0x20000, 0x3fffd, 1},
+	},
+}
+
+// isWide reports whether r takes two columns in terminals.
+func isWide(r rune) bool {
+	return unicode.Is(wideRanges, r)
+}
+
+// parseGlyph returns the single character of s.
+func parseGlyph(s string) (rune, error) {
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, fmt.Errorf("glyph %q must be a single character", s)
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return 0, fmt.Errorf("glyph %q is not valid UTF-8", s)
+	}
+	return r, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseGlyph(t *testing.T) {
+	tests := []struct {
+		s    string
+		want rune
+	}{
+		{"#", '#'},
+		{" ", ' '},
+		{"█", '█'},
+		{"·", '·'},
+		{"é", 'é'},
+	}
+	for _, tt := range tests {
+		got, err := parseGlyph(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("parseGlyph(%q) = %q, %v, want %q", tt.s, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseGlyphError(t *testing.T) {
+	for _, s := range []string{"", "oo", "e\u0301", "\xff", "█ "} {
+		if r, err := parseGlyph(s); err == nil {
+			t.Errorf("parseGlyph(%q) = %q, want error", s, r)
+		}
+	}
+}
+
+func TestGlyphsValidate(t *testing.T) {
+	tests := []struct {
+		g  Glyphs
+		ok bool
+	}{
+		{DefaultGlyphs, true},
+		{Glyphs{Alive: '█', Dead: '·'}, true},
+		{Glyphs{Alive: '●', Dead: '○'}, true},
+		{Glyphs{Alive: '世', Dead: ' '}, false},
+		{Glyphs{Alive: 'o', Dead: '🌑'}, false},
+		{Glyphs{Alive: '\t', Dead: ' '}, false},
+		{Glyphs{Alive: 'o', Dead: '\u0301'}, false},
+		{Glyphs{Alive: 'o', Dead: '\u200b'}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.g.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%q: Validate() = %v, want ok %v", []rune{tt.g.Alive, tt.g.Dead}, err, tt.ok)
+		}
+	}
+}
+
+func TestTextRendererGlyphs(t *testing.T) {
+	f := patternField([]string{"o..", ".o.", "..o"})
+	tests := []struct {
+		border bool
+		want   string
+	}{
+		{false, "█··\n·█·\n··█\n"},
+		{true, "┌───┐\n│█··│\n│·█·│\n│··█│\n└───┘\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		r := NewTextRenderer(&buf, ASCII)
+		r.Glyphs = Glyphs{Alive: '█', Dead: '·'}
+		r.Header = nil
+		r.Border = tt.border
+		if err := r.Render(f, 0); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("border %v: got %q, want %q", tt.border, got, tt.want)
+		}
+	}
+}
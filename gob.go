@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+)
+
+// fieldGob is gob representation of Field. Cells are packed in row-major
+// order, 8 cells in a byte from the least significant bit.
+type fieldGob struct {
+	Height, Width int
+	Rule          string
+	Topology      Topology
+	Packed        bool // whether the field is made by NewPackedField
+	Cells         []byte
+	Dying         []uint8 // dying states of Generations rules in row-major order
+}
+
+// lifeGob is gob representation of Life.
+type lifeGob struct {
+	Generation int
+	Field      fieldGob
+}
+
+func (f *Field) toGob() fieldGob {
+	fg := fieldGob{
+		Height:   f.h,
+		Width:    f.w,
+		Rule:     f.rule.String(),
+		Topology: f.topo,
+		Packed:   f.bits != nil,
+		Cells:    make([]byte, (f.h*f.w+7)/8),
+	}
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			if f.get(i, j) {
+				k := i*f.w + j
+				fg.Cells[k/8] |= 1 << uint(k%8)
+			}
+		}
+	}
+	if f.dying != nil {
+		fg.Dying = make([]uint8, 0, f.h*f.w)
+		for _, row := range f.dying {
+			fg.Dying = append(fg.Dying, row...)
+		}
+	}
+	return fg
+}
+
+func (fg *fieldGob) toField() (*Field, error) {
+	if fg.Width <= 0 || fg.Height <= 0 {
+		return nil, fmt.Errorf("gob: invalid field size %dx%d", fg.Width, fg.Height)
+	}
+	// the length of cells bounds the size of the field once the number of
+	// cells does not overflow.
+	if fg.Width > (math.MaxInt-7)/fg.Height {
+		return nil, fmt.Errorf("gob: field size %dx%d is too large", fg.Width, fg.Height)
+	}
+	n := fg.Height * fg.Width
+	if len(fg.Cells) != (n+7)/8 {
+		return nil, fmt.Errorf("gob: got %d bytes of cells, want %d", len(fg.Cells), (n+7)/8)
+	}
+	if fg.Dying != nil && len(fg.Dying) != n {
+		return nil, fmt.Errorf("gob: got %d dying states, want %d", len(fg.Dying), n)
+	}
+	rule, err := ParseRule(fg.Rule)
+	if err != nil {
+		return nil, fmt.Errorf("gob: %v", err)
+	}
+	if fg.Dying != nil && rule.states <= 2 {
+		return nil, fmt.Errorf("gob: dying states for rule %v without them", rule)
+	}
+	for k, d := range fg.Dying {
+		if int(d) > rule.states-2 {
+			return nil, fmt.Errorf("gob: invalid dying state %d of cell %d", d, k)
+		}
+	}
+	if _, ok := topologyNames[fg.Topology]; !ok {
+		return nil, fmt.Errorf("gob: unknown topology %v", fg.Topology)
+	}
+	var f *Field
+	if fg.Packed {
+		f = NewPackedField(fg.Height, fg.Width)
+	} else {
+		f = NewField(fg.Height, fg.Width)
+	}
+	f.rule, f.topo = rule, fg.Topology
+	for k := 0; k < n; k++ {
+		if fg.Cells[k/8]&(1<<uint(k%8)) != 0 {
+			f.put(k/fg.Width, k%fg.Width, true)
+		}
+	}
+	if rule.states > 2 {
+		f.dying = newDying(fg.Height, fg.Width)
+		if fg.Dying != nil {
+			copy(f.dying[0][:n], fg.Dying)
+		}
+	}
+	return f, nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (f *Field) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(f.toGob()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder.
+func (f *Field) GobDecode(data []byte) error {
+	var fg fieldGob
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&fg); err != nil {
+		return err
+	}
+	nf, err := fg.toField()
+	if err != nil {
+		return err
+	}
+	*f = *nf
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (l *Life) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(lifeGob{Generation: l.gen, Field: l.cur.toGob()}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder.
+func (l *Life) GobDecode(data []byte) error {
+	var lg lifeGob
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&lg); err != nil {
+		return err
+	}
+	cur, err := lg.Field.toField()
+	if err != nil {
+		return err
+	}
+	*l = Life{cur: cur, next: cur.blank(), gen: lg.Generation}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strings"
+	"testing"
+)
+
+func TestFieldGobRoundTrip(t *testing.T) {
+	for _, rule := range []string{"B3/S23", "B2/S345/C4"} {
+		r, err := ParseRule(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, topo := range []Topology{Torus, Fixed, Mirror} {
+			for _, packed := range []bool{false, true} {
+				l := lifeOf(randomField(500, 500, 0.3, 1, packed))
+				l.SetRule(r)
+				l.SetTopology(topo)
+				l.Run(3)
+
+				var buf bytes.Buffer
+				if err := gob.NewEncoder(&buf).Encode(l.cur); err != nil {
+					t.Fatal(err)
+				}
+				got := new(Field)
+				if err := gob.NewDecoder(&buf).Decode(got); err != nil {
+					t.Fatalf("%v %v packed %v: %v", rule, topo, packed, err)
+				}
+				if !got.Equal(l.cur) || !equalStates(got, l.cur) {
+					t.Errorf("%v %v packed %v: decoded field differs", rule, topo, packed)
+				}
+				if got.topo != topo || got.rule != l.cur.rule || (got.bits != nil) != packed {
+					t.Errorf("%v %v packed %v: decoded topology %v, rule %v, packed %v",
+						rule, topo, packed, got.topo, got.rule, got.bits != nil)
+				}
+			}
+		}
+	}
+}
+
+func TestFieldGobDecodeError(t *testing.T) {
+	tests := []struct {
+		name string
+		fg   fieldGob
+		want string // substring of the error
+	}{
+		{"zero size", fieldGob{Height: 0, Width: 3, Rule: "B3/S23"}, "invalid field size"},
+		{"overflow", fieldGob{Height: 1 << 32, Width: 1 << 32, Rule: "B3/S23"}, "too large"},
+		{"short cells", fieldGob{Height: 3, Width: 3, Rule: "B3/S23", Cells: []byte{0}}, "bytes of cells"},
+		{"bad rule", fieldGob{Height: 1, Width: 1, Rule: "B9/S", Cells: []byte{0}}, "rule"},
+		{"bad topology", fieldGob{Height: 1, Width: 1, Rule: "B3/S23", Topology: 99, Cells: []byte{0}}, "topology"},
+		{"dying of Conway's rule", fieldGob{Height: 1, Width: 2, Rule: "B3/S23", Cells: []byte{0}, Dying: []uint8{0, 0}}, "without them"},
+		{"dying length", fieldGob{Height: 1, Width: 2, Rule: "B2/S/C3", Cells: []byte{0}, Dying: []uint8{0}}, "dying states"},
+		{"dying out of range", fieldGob{Height: 1, Width: 2, Rule: "B2/S/C3", Cells: []byte{0}, Dying: []uint8{1, 2}}, "invalid dying state 2 of cell 1"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(tt.fg); err != nil {
+			t.Fatal(err)
+		}
+		err := new(Field).GobDecode(buf.Bytes())
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
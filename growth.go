@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxCells is the default limit of the number of cells of fields
+// growing by SetGrowth.
+const DefaultMaxCells = 1 << 24
+
+// ErrGrowthLimit is returned by GrowthErr when the field needs to grow
+// beyond the limit of SetGrowth.
+var ErrGrowthLimit = errors.New("field reached the limit of growth")
+
+// SetGrowth makes the field grow before each generation whenever live
+// cells are within margin cells of its edges, so that patterns behave as
+// on an unbounded plane. The field grows by half of its size toward such
+// edges, and the cells are moved so that they keep their positions on the
+// plane. The field stops growing when it would exceed maxCells cells, or
+// never when maxCells is 0. margin 0 disables growth.
+func (l *Life) SetGrowth(margin, maxCells int) error {
+	if margin < 0 {
+		return fmt.Errorf("growth: negative margin %d", margin)
+	}
+	if maxCells < 0 {
+		return fmt.Errorf("growth: negative limit %d", maxCells)
+	}
+	l.growMargin, l.growMax = margin, maxCells
+	l.growErr = nil
+	return nil
+}
+
+// GrowthErr returns ErrGrowthLimit once the field stopped growing at the
+// limit of SetGrowth, and nil otherwise.
+func (l *Life) GrowthErr() error {
+	return l.growErr
+}
+
+// Origin returns the position of the cell at (0, 0) on the plane. It is
+// (0, 0) until the field grows toward the top or the left.
+func (f *Field) Origin() (top, left int) {
+	return f.top, f.left
+}
+
+// grow grows the field as set by SetGrowth when live cells are near its
+// edges. Elementary cellular automata do not grow, as their rows are
+// generations.
+func (l *Life) grow() {
+	m := l.growMargin
+	if m == 0 || l.growErr != nil || l.cur.rule.elementary {
+		return
+	}
+	top, left, bottom, right, ok := l.cur.BoundingBox()
+	if !ok {
+		return
+	}
+	bottom, right = bottom+1, right+1
+	h, w := l.cur.h, l.cur.w
+	// rows or columns added before and after the field.
+	extend := func(lo, hi, size int) (before, after int) {
+		if lo < m {
+			before = size / 2
+			if before < m-lo {
+				before = m - lo
+			}
+		}
+		if hi > size-m {
+			after = size / 2
+			if after < hi-(size-m) {
+				after = hi - (size - m)
+			}
+		}
+		return before, after
+	}
+	addT, addB := extend(top, bottom, h)
+	addL, addR := extend(left, right, w)
+	if addT+addB+addL+addR == 0 {
+		return
+	}
+	newH, newW := h+addT+addB, w+addL+addR
+	if l.growMax > 0 && (newH > l.growMax/newW || newH*newW > l.growMax) {
+		l.growErr = ErrGrowthLimit
+		return
+	}
+	l.cur.resizeAt(newH, newW, addT, addL)
+	l.cur.top -= addT
+	l.cur.left -= addL
+	l.next = l.cur.blank()
+	l.activeFor = nil
+}
+
+// planeWindow returns a field of h x w cells holding the cells of f from
+// (top, left) on the plane. Cells outside of f are dead.
+func (f *Field) planeWindow(top, left, h, w int) *Field {
+	if f.top == top && f.left == left && f.h == h && f.w == w {
+		return f
+	}
+	n := NewField(h, w)
+	n.rule = f.rule
+	n.topo = f.topo
+	n.top, n.left = top, left
+	for i := 0; i < h; i++ {
+		r := top + i - f.top
+		if r < 0 || r >= f.h {
+			continue
+		}
+		for j := 0; j < w; j++ {
+			if c := left + j - f.left; c >= 0 && c < f.w && f.get(r, c) {
+				n.cs[i][j] = true
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGrowthGlider(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []string
+		dr, dc int // displacement on the plane every 4 generations
+	}{
+		{"down right", library["glider"], 1, 1},
+		{"up left", []string{"ooo", "o..", ".o."}, -1, -1},
+	}
+	const maxCells = 10000
+	for _, tt := range tests {
+		for _, topo := range []Topology{Torus, Fixed} {
+			l, err := newLifeWithMargin(patternField(tt.rows), 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.SetTopology(topo)
+			if err := l.SetGrowth(2, maxCells); err != nil {
+				t.Fatal(err)
+			}
+			for g := 1; g <= 200; g++ {
+				l.Next()
+				if n := l.Population(); n != 5 {
+					t.Fatalf("%s %v: population %d at generation %d", tt.name, topo, n, g)
+				}
+				if l.cur.h*l.cur.w > maxCells {
+					t.Fatalf("%s %v: field of %dx%d cells exceeds %d", tt.name, topo, l.cur.w, l.cur.h, maxCells)
+				}
+				if g%4 != 0 {
+					continue
+				}
+				top, left, bottom, right, _ := l.cur.BoundingBox()
+				window := l.cur.planeWindow(l.cur.top+top, l.cur.left+left, bottom-top+1, right-left+1)
+				if got := fieldRows(window); !reflect.DeepEqual(got, tt.rows) {
+					t.Fatalf("%s %v: got %q at generation %d, want %q", tt.name, topo, got, g, tt.rows)
+				}
+				// the glider keeps its position on the plane.
+				r, c := l.cur.Origin()
+				if r+top != 2+tt.dr*g/4 || c+left != 2+tt.dc*g/4 {
+					t.Fatalf("%s %v: glider at (%d, %d) of the plane at generation %d, want (%d, %d)",
+						tt.name, topo, r+top, c+left, g, 2+tt.dr*g/4, 2+tt.dc*g/4)
+				}
+			}
+			if err := l.GrowthErr(); err != nil {
+				t.Errorf("%s %v: %v", tt.name, topo, err)
+			}
+		}
+	}
+}
+
+func TestGrowthLimit(t *testing.T) {
+	l, err := newLifeWithMargin(patternField(library["glider"]), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetTopology(Fixed)
+	const maxCells = 400
+	if err := l.SetGrowth(2, maxCells); err != nil {
+		t.Fatal(err)
+	}
+	for g := 0; g < 200 && l.GrowthErr() == nil; g++ {
+		l.Next()
+	}
+	if err := l.GrowthErr(); err != ErrGrowthLimit {
+		t.Fatalf("GrowthErr() = %v, want %v", err, ErrGrowthLimit)
+	}
+	if n := l.cur.h * l.cur.w; n > maxCells {
+		t.Errorf("field of %d cells exceeds %d", n, maxCells)
+	}
+
+	for _, args := range [][2]int{{-1, 0}, {1, -1}} {
+		if err := l.SetGrowth(args[0], args[1]); err == nil {
+			t.Errorf("SetGrowth%v succeeded", args)
+		}
+	}
+	if err := l.SetGrowth(2, 0); err != nil || l.GrowthErr() != nil {
+		t.Errorf("SetGrowth(2, 0) = %v, GrowthErr() = %v", err, l.GrowthErr())
+	}
+}
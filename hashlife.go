@@ -0,0 +1,351 @@
+package main
+
+import "errors"
+
+// hlNode is a canonical node of HashLife quadtree. A node of level k holds
+// 2^k x 2^k cells, and nodes of level 0 are single cells.
+type hlNode struct {
+	nw, ne, sw, se *hlNode
+	level          uint
+	pop            int // number of live cells
+}
+
+type hlKey struct {
+	nw, ne, sw, se *hlNode
+}
+
+type hlStepKey struct {
+	n *hlNode
+	j uint
+}
+
+// DefaultHashLifeNodes is the default limit of the number of nodes and
+// memoized steps kept by HashLife.
+const DefaultHashLifeNodes = 1 << 21
+
+// HashLife is a lifegame engine based on Gosper's HashLife algorithm.
+// The universe is represented as a quadtree of canonical nodes, and the
+// results of advancing nodes are memoized, so that huge and regular patterns
+// can be advanced by many generations at once.
+// Unlike Field, the universe is an unbounded plane.
+// The tables of nodes and steps are flushed between steps of Advance when
+// they grow beyond the limit set by SetMaxNodes, so that memory use stays
+// bounded.
+type HashLife struct {
+	rule       Rule
+	nodes      map[hlKey]*hlNode
+	steps      map[hlStepKey]*hlNode
+	maxNodes   int       // limit of len(nodes)+len(steps)
+	empty      []*hlNode // empty node of each level
+	dead, live *hlNode
+	root       *hlNode
+	top, left  int64 // coordinate of the top left corner of root
+	gen        uint64
+}
+
+// NewHashLife returns HashLife engine starting from the cells and the rule
+// of f. The cell at (r, c) in f is placed at (r, c) in the universe.
+func NewHashLife(f *Field) (*HashLife, error) {
+	if f.rule.birth&1 != 0 {
+		return nil, errors.New("hashlife: rules with B0 are not supported")
+	}
+	if f.rule.states > 2 {
+		return nil, errors.New("hashlife: Generations rules are not supported")
+	}
+	if f.rule.nbhd != Moore {
+		return nil, errors.New("hashlife: neighborhoods other than Moore are not supported")
+	}
+	if f.rule.mapped {
+		return nil, errors.New("hashlife: MAP rules are not supported")
+	}
+	if f.rule.elementary {
+		return nil, errors.New("hashlife: elementary cellular automata are not supported")
+	}
+	if f.rule.radius > 0 {
+		return nil, errors.New("hashlife: Larger than Life rules are not supported")
+	}
+	h := &HashLife{
+		rule:     f.rule,
+		nodes:    make(map[hlKey]*hlNode),
+		steps:    make(map[hlStepKey]*hlNode),
+		maxNodes: DefaultHashLifeNodes,
+		dead:     &hlNode{},
+		live:     &hlNode{pop: 1},
+	}
+	h.empty = []*hlNode{h.dead}
+	level := uint(1)
+	for 1<<level < f.h || 1<<level < f.w {
+		level++
+	}
+	h.root = h.build(f, 0, 0, level)
+	return h, nil
+}
+
+// SetMaxNodes sets the limit of the number of nodes and memoized steps.
+// n less than 1 is treated as 1.
+func (h *HashLife) SetMaxNodes(n int) {
+	if n < 1 {
+		n = 1
+	}
+	h.maxNodes = n
+}
+
+// flush drops all memoized steps and all nodes except those of root and
+// the empty nodes.
+func (h *HashLife) flush() {
+	h.nodes = make(map[hlKey]*hlNode)
+	h.steps = make(map[hlStepKey]*hlNode)
+	for _, e := range h.empty[1:] {
+		h.nodes[hlKey{e.nw, e.ne, e.sw, e.se}] = e
+	}
+	if h.root != nil {
+		h.keep(h.root)
+	}
+}
+
+// keep adds n and its descendants to the nodes.
+func (h *HashLife) keep(n *hlNode) {
+	if n.level == 0 || n.pop == 0 {
+		return
+	}
+	k := hlKey{n.nw, n.ne, n.sw, n.se}
+	if _, ok := h.nodes[k]; ok {
+		return
+	}
+	h.nodes[k] = n
+	h.keep(n.nw)
+	h.keep(n.ne)
+	h.keep(n.sw)
+	h.keep(n.se)
+}
+
+// build returns the node of level holding cells of f from (r, c).
+func (h *HashLife) build(f *Field, r, c int, level uint) *hlNode {
+	if r >= f.h || c >= f.w {
+		return h.emptyNode(level)
+	}
+	if level == 0 {
+		if f.get(r, c) {
+			return h.live
+		}
+		return h.dead
+	}
+	half := 1 << (level - 1)
+	return h.join(
+		h.build(f, r, c, level-1),
+		h.build(f, r, c+half, level-1),
+		h.build(f, r+half, c, level-1),
+		h.build(f, r+half, c+half, level-1))
+}
+
+// join returns the canonical node which has the four children.
+func (h *HashLife) join(nw, ne, sw, se *hlNode) *hlNode {
+	k := hlKey{nw, ne, sw, se}
+	if n, ok := h.nodes[k]; ok {
+		return n
+	}
+	n := &hlNode{
+		nw: nw, ne: ne, sw: sw, se: se,
+		level: nw.level + 1,
+		pop:   nw.pop + ne.pop + sw.pop + se.pop,
+	}
+	h.nodes[k] = n
+	return n
+}
+
+// emptyNode returns the node of level with no live cells.
+func (h *HashLife) emptyNode(level uint) *hlNode {
+	for uint(len(h.empty)) <= level {
+		e := h.empty[len(h.empty)-1]
+		h.empty = append(h.empty, h.join(e, e, e, e))
+	}
+	return h.empty[level]
+}
+
+// center returns the node of one level lower at the center of n.
+func (h *HashLife) center(n *hlNode) *hlNode {
+	return h.join(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw)
+}
+
+// expand doubles the size of root keeping its cells at the center.
+func (h *HashLife) expand() {
+	n := h.root
+	e := h.emptyNode(n.level - 1)
+	h.root = h.join(
+		h.join(e, e, e, n.nw),
+		h.join(e, e, n.ne, e),
+		h.join(e, n.sw, e, e),
+		h.join(n.se, e, e, e))
+	h.top -= 1 << (n.level - 1)
+	h.left -= 1 << (n.level - 1)
+}
+
+// step returns the center of n, which is one level lower than n, advanced
+// by 2^j generations. j must be at most n.level-2.
+func (h *HashLife) step(n *hlNode, j uint) *hlNode {
+	if n.pop == 0 {
+		return h.emptyNode(n.level - 1)
+	}
+	k := hlStepKey{n, j}
+	if s, ok := h.steps[k]; ok {
+		return s
+	}
+	var s *hlNode
+	if n.level == 2 {
+		s = h.step4x4(n)
+	} else {
+		// nine overlapping sub nodes of one level lower, advanced by 2^j
+		// generations (or 2^(level-3) for the first half of full step).
+		jj := j
+		if jj > n.level-3 {
+			jj = n.level - 3
+		}
+		c1 := h.step(n.nw, jj)
+		c2 := h.step(h.join(n.nw.ne, n.ne.nw, n.nw.se, n.ne.sw), jj)
+		c3 := h.step(n.ne, jj)
+		c4 := h.step(h.join(n.nw.sw, n.nw.se, n.sw.nw, n.sw.ne), jj)
+		c5 := h.step(h.center(n), jj)
+		c6 := h.step(h.join(n.ne.sw, n.ne.se, n.se.nw, n.se.ne), jj)
+		c7 := h.step(n.sw, jj)
+		c8 := h.step(h.join(n.sw.ne, n.se.nw, n.sw.se, n.se.sw), jj)
+		c9 := h.step(n.se, jj)
+		if j < n.level-2 {
+			// the steps above already made 2^j generations.
+			s = h.join(
+				h.join(c1.se, c2.sw, c4.ne, c5.nw),
+				h.join(c2.se, c3.sw, c5.ne, c6.nw),
+				h.join(c4.se, c5.sw, c7.ne, c8.nw),
+				h.join(c5.se, c6.sw, c8.ne, c9.nw))
+		} else {
+			s = h.join(
+				h.step(h.join(c1, c2, c4, c5), jj),
+				h.step(h.join(c2, c3, c5, c6), jj),
+				h.step(h.join(c4, c5, c7, c8), jj),
+				h.step(h.join(c5, c6, c8, c9), jj))
+		}
+	}
+	h.steps[k] = s
+	return s
+}
+
+// step4x4 returns the center 2 x 2 cells of 4 x 4 node n advanced by one generation.
+func (h *HashLife) step4x4(n *hlNode) *hlNode {
+	var cells [4][4]bool
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			cells[r][c] = h.cell(n, r, c)
+		}
+	}
+	var next [4]*hlNode
+	for i := 0; i < 4; i++ {
+		r, c := 1+i/2, 1+i%2
+		alive := 0
+		for dr := -1; dr <= 1; dr++ {
+			for dc := -1; dc <= 1; dc++ {
+				if (dr != 0 || dc != 0) && cells[r+dr][c+dc] {
+					alive++
+				}
+			}
+		}
+		next[i] = h.dead
+		if h.rule.next(cells[r][c], alive) {
+			next[i] = h.live
+		}
+	}
+	return h.join(next[0], next[1], next[2], next[3])
+}
+
+// cell returns the state of the cell at (r, c) relative to the top left of n.
+func (h *HashLife) cell(n *hlNode, r, c int) bool {
+	for n.level > 0 {
+		if n.pop == 0 {
+			return false
+		}
+		half := 1 << (n.level - 1)
+		switch {
+		case r < half && c < half:
+			n = n.nw
+		case r < half:
+			n, c = n.ne, c-half
+		case c < half:
+			n, r = n.sw, r-half
+		default:
+			n, r, c = n.se, r-half, c-half
+		}
+	}
+	return n.pop == 1
+}
+
+// centered reports whether all live cells of root are in its center quarter.
+func (h *HashLife) centered() bool {
+	return h.center(h.center(h.root)).pop == h.root.pop
+}
+
+// Advance advances the universe by n generations. n is split into powers of
+// two, and each of them is calculated at once.
+func (h *HashLife) Advance(n uint64) {
+	for j := uint(0); j < 64 && n>>j != 0; j++ {
+		if n&(1<<j) == 0 {
+			continue
+		}
+		// pattern can grow by 2^j cells at most, so keep it in the center
+		// quarter of root large enough.
+		for h.root.level < j+3 || !h.centered() {
+			h.expand()
+		}
+		if len(h.nodes)+len(h.steps) >= h.maxNodes {
+			h.flush()
+		}
+		level := h.root.level
+		h.root = h.step(h.root, j)
+		h.top += 1 << (level - 2)
+		h.left += 1 << (level - 2)
+		h.gen += 1 << j
+	}
+}
+
+// Generation returns the number of generations advanced.
+func (h *HashLife) Generation() uint64 {
+	return h.gen
+}
+
+// Population returns the number of live cells in the universe.
+func (h *HashLife) Population() int {
+	return h.root.pop
+}
+
+// Alive reports whether the cell at (r, c) in the universe is alive.
+func (h *HashLife) Alive(r, c int64) bool {
+	r, c = r-h.top, c-h.left
+	size := int64(1) << h.root.level
+	if r < 0 || r >= size || c < 0 || c >= size {
+		return false
+	}
+	return h.cell(h.root, int(r), int(c))
+}
+
+// Field returns a field of height x width cells, holding the cells of the
+// universe from (0, 0).
+func (h *HashLife) Field(height, width int) *Field {
+	f := NewField(height, width)
+	f.rule = h.rule
+	h.paint(f, h.root, h.top, h.left)
+	return f
+}
+
+// paint sets live cells of n placed at (r, c) into f.
+func (h *HashLife) paint(f *Field, n *hlNode, r, c int64) {
+	size := int64(1) << n.level
+	if n.pop == 0 || r >= int64(f.h) || c >= int64(f.w) || r+size <= 0 || c+size <= 0 {
+		return
+	}
+	if n.level == 0 {
+		f.put(int(r), int(c), true)
+		return
+	}
+	half := size / 2
+	h.paint(f, n.nw, r, c)
+	h.paint(f, n.ne, r, c+half)
+	h.paint(f, n.sw, r+half, c)
+	h.paint(f, n.se, r+half, c+half)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHashLifeCrossCheck(t *testing.T) {
+	rules := []string{"B3/S23", "B36/S23", "B3/S012345678"}
+	for seed := int64(0); seed < 5; seed++ {
+		rnd := rand.New(rand.NewSource(seed))
+		r, err := ParseRule(rules[seed%int64(len(rules))])
+		if err != nil {
+			t.Fatal(err)
+		}
+		// random cells at the center of the field, far from the edges.
+		n := 120
+		l := New(n, n, WithRule(r), WithTopology(Fixed))
+		for i := 50; i < 70; i++ {
+			for j := 50; j < 70; j++ {
+				l.cur.Set(i, j, rnd.Intn(2) == 0)
+			}
+		}
+		h, err := NewHashLife(l.cur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for l.gen < 40 {
+			steps := rnd.Intn(4)
+			for k := 0; k < steps; k++ {
+				l.Step()
+			}
+			h.Advance(uint64(steps))
+			if h.Generation() != uint64(l.gen) || !h.Field(n, n).Equal(l.cur) {
+				t.Fatalf("%v seed %d: generation %d differs", r, seed, l.gen)
+			}
+		}
+	}
+}
+
+func TestHashLifePatterns(t *testing.T) {
+	for _, name := range []string{"glider", "lwss", "pulsar", "r-pentomino", "acorn", "gosper-gun"} {
+		for _, maxNodes := range []int{DefaultHashLifeNodes, 1000} {
+			// lwss moving by 500 cells in 1000 generations does not reach the
+			// edges.
+			l, err := NewLifeFromPattern(name, 520)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.SetTopology(Fixed)
+			h, err := NewHashLife(l.cur)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.SetMaxNodes(maxNodes)
+			rnd := rand.New(rand.NewSource(1))
+			for l.gen < 1000 {
+				steps := 1 + rnd.Intn(100)
+				if steps > 1000-l.gen {
+					steps = 1000 - l.gen
+				}
+				l.Run(steps)
+				h.Advance(uint64(steps))
+				if h.Population() != l.Population() || !h.Field(l.cur.h, l.cur.w).Equal(l.cur) {
+					t.Fatalf("%s with %d nodes: generation %d differs", name, maxNodes, l.gen)
+				}
+			}
+		}
+	}
+}
+
+// TestHashLifeGosperGun jumps Gosper glider gun 2^20 generations, and checks
+// it against the gun calculated for a few hundred generations: the gun
+// repeats every 30 generations emitting a glider of 5 cells.
+func TestHashLifeGosperGun(t *testing.T) {
+	const gens = 1 << 20
+	l, err := NewLifeFromPattern("gosper-gun", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gun := l.cur.Clone()
+	h, err := NewHashLife(gun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Advance(gens)
+	if h.Generation() != gens {
+		t.Fatalf("Generation() = %d, want %d", h.Generation(), gens)
+	}
+
+	base := 300 + gens%30
+	s, err := NewSparseField(gun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s.Generation() < base {
+		s.Next()
+	}
+	if want := s.Population() + 5*(gens-base)/30; h.Population() != want {
+		t.Errorf("Population() = %d, want %d", h.Population(), want)
+	}
+	if want := s.Field(0, 0, gun.h, gun.w); !h.Field(gun.h, gun.w).Equal(want) {
+		t.Error("the gun differs")
+	}
+}
+
+func BenchmarkHashLifeGosperGun(b *testing.B) {
+	l, err := NewLifeFromPattern("gosper-gun", 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		h, err := NewHashLife(l.cur)
+		if err != nil {
+			b.Fatal(err)
+		}
+		h.Advance(1 << 20)
+	}
+}
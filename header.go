@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultHeader is the header line displayed above each generation unless
+// specified.
+var DefaultHeader = template.Must(ParseHeader("---------- {{.Gen}}th generation"))
+
+// ParseHeader parses the template of header lines. The template is executed
+// with the generation, and can refer to .Gen, .Population, .Width, .Height
+// and .Rule. It is an error if the template fails for a generation.
+func ParseHeader(text string) (*template.Template, error) {
+	t, err := template.New("header").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// report errors such as unknown fields now rather than for each frame.
+	if err := t.Execute(&bytes.Buffer{}, &headerData{f: NewField(1, 1)}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// headerData is the data of header templates.
+type headerData struct {
+	Gen int
+	f   *Field
+	pop int // population plus 1, or 0 when it is not counted yet
+}
+
+// Population returns the number of live cells, counted once per header.
+func (d *headerData) Population() int {
+	if d.pop == 0 {
+		d.pop = d.f.Population() + 1
+	}
+	return d.pop - 1
+}
+
+// Width returns the width of the field.
+func (d *headerData) Width() int { return d.f.w }
+
+// Height returns the height of the field.
+func (d *headerData) Height() int { return d.f.h }
+
+// Rule returns the rule of the field.
+func (d *headerData) Rule() Rule { return d.f.rule }
+
+// renderHeader appends the header line of generation gen to buf. Nothing is
+// appended when t is nil. suffix is appended to the line.
+func renderHeader(buf *bytes.Buffer, t *template.Template, f *Field, gen int, suffix string) error {
+	if t == nil {
+		return nil
+	}
+	var line bytes.Buffer
+	if err := t.Execute(&line, &headerData{Gen: gen, f: f}); err != nil {
+		return fmt.Errorf("header: %v", err)
+	}
+	// keep the header in a line so that the layout of frames is fixed.
+	buf.WriteString(strings.Replace(line.String(), "\n", " ", -1))
+	buf.WriteString(suffix)
+	buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math"
+)
+
+// Default parameters of activity tracked in Heatmap render mode.
+const (
+	DefaultHeatDecay  = 0.9
+	DefaultHeatWindow = 16
+)
+
+// newHeat returns activities of h x w cells, all zero.
+func newHeat(h, w int) [][]float64 {
+	buf := make([]float64, h*w)
+	heat := make([][]float64, h)
+	for i := range heat {
+		heat[i] = buf[i*w : (i+1)*w]
+	}
+	return heat
+}
+
+// Activity returns how often the cell of r & c has been alive recently,
+// which is 1 for cells alive for the whole window of TrackActivity and 0
+// for cells not alive in the window. It is 0 for all cells unless activity
+// is tracked, which is done in Heatmap render mode.
+func (f *Field) Activity(r, c int) float64 {
+	if f.heat == nil {
+		return 0
+	}
+	return f.heat[r][c]
+}
+
+// TrackActivity starts tracking activity of cells. Each generation, the
+// activity of each cell decays by the factor decay, and increases when the
+// cell is alive. Activity is scaled so that cells alive for the last window
+// generations have activity 1, and it is dropped to 0 when it falls below
+// that of a cell alive only window generations ago. Live cells of current
+// generation are treated as alive for a generation.
+func (l *Life) TrackActivity(decay float64, window int) error {
+	if !(decay > 0 && decay < 1) {
+		return errors.New("activity decay must be between 0 and 1")
+	}
+	if window <= 0 {
+		return errors.New("activity window must be positive")
+	}
+	l.heatDecay = decay
+	l.heatGain = (1 - decay) / (1 - math.Pow(decay, float64(window)))
+	l.heatMin = l.heatGain * math.Pow(decay, float64(window))
+	l.cur.heat = newHeat(l.cur.h, l.cur.w)
+	for i := 0; i < l.cur.h; i++ {
+		for j := 0; j < l.cur.w; j++ {
+			if l.cur.get(i, j) {
+				l.cur.heat[i][j] = l.heatGain
+			}
+		}
+	}
+	l.next.heat = newHeat(l.next.h, l.next.w)
+	l.clearHistory()
+	return nil
+}
+
+// activity returns the activity of next generation of the cell whose
+// activity is v.
+func (l *Life) activity(v float64, alive bool) float64 {
+	v *= l.heatDecay
+	if alive {
+		v += l.heatGain
+	}
+	if v < l.heatMin {
+		v = 0
+	}
+	return v
+}
+
+// renderHeatmap appends the field to buf with each cell displayed as a
+// character of densityRamp by its activity.
+func (f *Field) renderHeatmap(buf *bytes.Buffer) {
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			buf.WriteByte(densityChar(f.Activity(i, j)))
+		}
+		buf.WriteByte('\n')
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestTrackActivity(t *testing.T) {
+	l, err := NewLifeFromPattern("blinker", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// gain is 8/15, so that 4 generations alive sum up to 1.
+	if err := l.TrackActivity(0.5, 4); err != nil {
+		t.Fatal(err)
+	}
+	gain := 8.0 / 15
+	tests := []struct {
+		r, c int
+		want []float64 // activity from generation 0
+	}{
+		{2, 3, []float64{gain, gain * 1.5, gain * 1.75, 1}},          // center, always alive
+		{2, 2, []float64{gain, gain / 2, gain * 1.25, gain * 0.625}}, // alive in even generations
+		{1, 3, []float64{0, gain, gain / 2, gain * 1.25}},            // alive in odd generations
+		{1, 2, []float64{0, 0, 0, 0}},                                // never alive
+	}
+	for g := 0; g < 4; g++ {
+		for _, tt := range tests {
+			if got := l.cur.Activity(tt.r, tt.c); math.Abs(got-tt.want[g]) > 1e-9 {
+				t.Errorf("generation %d: Activity(%d, %d) = %v, want %v", g, tt.r, tt.c, got, tt.want[g])
+			}
+		}
+		l.Next()
+	}
+}
+
+func TestTrackActivityDecay(t *testing.T) {
+	// A lone cell dies at once, and its activity halves each generation
+	// until it falls below that of a cell alive 4 generations ago.
+	l, err := newLifeWithMargin(patternField([]string{"o"}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.TrackActivity(0.5, 4); err != nil {
+		t.Fatal(err)
+	}
+	want := 8.0 / 15
+	for g := 0; g <= 4; g++ {
+		if got := l.cur.Activity(1, 1); math.Abs(got-want) > 1e-9 {
+			t.Errorf("generation %d: Activity = %v, want %v", g, got, want)
+		}
+		l.Next()
+		want /= 2
+	}
+	if got := l.cur.Activity(1, 1); got != 0 {
+		t.Errorf("generation 5: Activity = %v, want 0", got)
+	}
+}
+
+func TestTrackActivityError(t *testing.T) {
+	tests := []struct {
+		decay  float64
+		window int
+	}{
+		{0, 4},
+		{1, 4},
+		{-0.5, 4},
+		{math.NaN(), 4},
+		{0.5, 0},
+		{0.5, -1},
+	}
+	for _, tt := range tests {
+		l, _ := NewLifeFromPattern("block", 1)
+		if err := l.TrackActivity(tt.decay, tt.window); err == nil {
+			t.Errorf("TrackActivity(%v, %d) succeeded", tt.decay, tt.window)
+		}
+	}
+}
+
+func TestRenderHeatmap(t *testing.T) {
+	l, err := NewLifeFromPattern("block", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetRenderMode(Heatmap)
+	for g := 0; g < DefaultHeatWindow-1; g++ {
+		l.Next()
+	}
+	var buf bytes.Buffer
+	l.cur.renderHeatmap(&buf)
+	want := "    \n @@ \n @@ \n    \n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Neighborhood is the set of cells counted as neighbors of each cell.
+type Neighborhood int
+
+const (
+	// Moore counts the eight cells around each cell.
+	Moore Neighborhood = iota
+	// Hexagonal counts the six cells adjacent on the hexagonal lattice,
+	// where odd rows are shifted right by half a cell. The height of the
+	// field should be even so that the rows alternate on torus.
+	Hexagonal
+	// VonNeumann counts the four cells above, below, left and right of
+	// each cell.
+	VonNeumann
+)
+
+var neighborhoodNames = map[Neighborhood]string{
+	Moore:      "moore",
+	Hexagonal:  "hexagonal",
+	VonNeumann: "vonneumann",
+}
+
+// neighborhoodSuffixes is the suffixes of rule strings of neighborhoods.
+var neighborhoodSuffixes = map[Neighborhood]string{
+	Hexagonal:  "H",
+	VonNeumann: "V",
+}
+
+// neighborhoodSizes is the number of neighbors of each neighborhood.
+var neighborhoodSizes = map[Neighborhood]int{
+	Moore:      8,
+	Hexagonal:  6,
+	VonNeumann: 4,
+}
+
+// String returns the name of the neighborhood.
+func (n Neighborhood) String() string {
+	if name, ok := neighborhoodNames[n]; ok {
+		return name
+	}
+	return fmt.Sprintf("Neighborhood(%d)", int(n))
+}
+
+// parseNeighborhood returns the neighborhood of name.
+func parseNeighborhood(name string) (Neighborhood, error) {
+	for n, s := range neighborhoodNames {
+		if s == name {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown neighborhood %q", name)
+}
+
+// Neighborhood returns the neighborhood of the rule.
+func (r Rule) Neighborhood() Neighborhood {
+	return r.nbhd
+}
+
+// WithNeighborhood returns the rule counting neighbors in n instead. It is
+// an error if the rule has neighbor counts larger than the size of n, or
+// the rule is of Larger than Life, MAP rule or elementary cellular automaton.
+func (r Rule) WithNeighborhood(n Neighborhood) (Rule, error) {
+	size, ok := neighborhoodSizes[n]
+	if !ok {
+		return Rule{}, fmt.Errorf("unknown neighborhood %v", n)
+	}
+	if r.radius > 0 {
+		return Rule{}, fmt.Errorf("rule %v: neighborhood of Larger than Life rules is fixed", r)
+	}
+	if r.mapped {
+		return Rule{}, fmt.Errorf("rule %v: neighborhood of MAP rules is fixed", r)
+	}
+	if r.elementary {
+		return Rule{}, fmt.Errorf("rule %v: elementary cellular automata have no neighborhood", r)
+	}
+	if (r.birth|r.survival)>>uint(size+1) != 0 {
+		return Rule{}, fmt.Errorf("rule %v: neighbor counts of %v neighborhood must be at most %d", r, n, size)
+	}
+	r.nbhd = n
+	return r, nil
+}
+
+// hexOffsets is the offsets of row and column of the neighbors on the
+// hexagonal lattice, indexed by the parity of the row.
+var hexOffsets = [2][6][2]int{
+	{{-1, -1}, {-1, 0}, {0, -1}, {0, 1}, {1, -1}, {1, 0}},
+	{{-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, 0}, {1, 1}},
+}
+
+// hexNeighbors returns the number of live cells among the six cells around
+// the cell of r & c on the hexagonal lattice.
+func (f *Field) hexNeighbors(r, c int) int {
+	alive := 0
+	for _, d := range hexOffsets[r&1] {
+		if f.Alive(r+d[0], c+d[1]) {
+			alive++
+		}
+	}
+	return alive
+}
+
+// vonNeumannNeighbors returns the number of live cells among the four cells
+// above, below, left and right of the cell of r & c.
+func (f *Field) vonNeumannNeighbors(r, c int) int {
+	alive := 0
+	for _, d := range [4][2]int{{-1, 0}, {0, -1}, {0, 1}, {1, 0}} {
+		if f.Alive(r+d[0], c+d[1]) {
+			alive++
+		}
+	}
+	return alive
+}
+
+// renderHex appends the field to buf with a space between cells, and odd
+// rows shifted right by a character, so that the lattice is hexagonal.
+func (f *Field) renderHex(buf *bytes.Buffer, g Glyphs) {
+	for i := 0; i < f.h; i++ {
+		if i&1 != 0 {
+			buf.WriteRune(g.Dead)
+		}
+		for j := 0; j < f.w; j++ {
+			if j > 0 {
+				buf.WriteRune(g.Dead)
+			}
+			if f.get(i, j) {
+				buf.WriteRune(g.Alive)
+			} else {
+				buf.WriteRune(g.Dead)
+			}
+		}
+		if i&1 == 0 {
+			buf.WriteRune(g.Dead)
+		}
+		buf.WriteByte('\n')
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHexNeighbors(t *testing.T) {
+	rule, err := ParseRule("B2/S34H")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := []string{".....", "..o..", ".....", "....."}
+	topLeft := []string{"o....", ".....", ".....", "....."}
+	bottomRight := []string{".....", ".....", ".....", "....o"}
+	tests := []struct {
+		rows []string
+		topo Topology
+		want []string // neighbor counts of each cell
+	}{
+		// odd rows are shifted right, so (1, 2) is adjacent to (0, 2),
+		// (0, 3), (2, 2) and (2, 3) above and below.
+		{inner, Torus, []string{"00110", "01010", "00110", "00000"}},
+		{inner, Fixed, []string{"00110", "01010", "00110", "00000"}},
+		{inner, Mirror, []string{"00110", "01010", "00110", "00000"}},
+		{topLeft, Torus, []string{"01001", "10001", "00000", "10001"}},
+		{topLeft, Fixed, []string{"01000", "10000", "00000", "00000"}},
+		{topLeft, Mirror, []string{"32000", "10000", "00000", "00000"}},
+		{bottomRight, Torus, []string{"10001", "00000", "10001", "10010"}},
+		{bottomRight, Fixed, []string{"00000", "00000", "00001", "00010"}},
+		{bottomRight, Mirror, []string{"00000", "00000", "00001", "00023"}},
+	}
+	for _, tt := range tests {
+		f := patternField(tt.rows)
+		f.SetRule(rule)
+		f.SetTopology(tt.topo)
+		if got := neighborCounts(f); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q %v: got %q, want %q", tt.rows, tt.topo, got, tt.want)
+		}
+	}
+}
+
+func TestVonNeumannNeighbors(t *testing.T) {
+	rule, err := ParseRule("B13/S012V")
+	if err != nil {
+		t.Fatal(err)
+	}
+	corner := []string{"o....", ".....", ".....", "....."}
+	inner := []string{".....", ".....", "..o..", "....."}
+	tests := []struct {
+		rows []string
+		topo Topology
+		want []string // neighbor counts of each cell
+	}{
+		{inner, Torus, []string{"00000", "00100", "01010", "00100"}},
+		{inner, Fixed, []string{"00000", "00100", "01010", "00100"}},
+		{corner, Torus, []string{"01001", "10000", "00000", "10000"}},
+		{corner, Fixed, []string{"01000", "10000", "00000", "00000"}},
+		{corner, Mirror, []string{"21000", "10000", "00000", "00000"}},
+	}
+	for _, tt := range tests {
+		f := patternField(tt.rows)
+		f.SetRule(rule)
+		f.SetTopology(tt.topo)
+		if got := neighborCounts(f); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q %v: got %q, want %q", tt.rows, tt.topo, got, tt.want)
+		}
+	}
+}
+
+func TestWithNeighborhood(t *testing.T) {
+	// a live cell gives birth to all of its neighbors.
+	rule, err := ParseRule("B1/S01234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := []string{".....", ".....", "..o..", ".....", "....."}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"moore", []string{".....", ".ooo.", ".ooo.", ".ooo.", "....."}},
+		{"vonneumann", []string{".....", "..o..", ".ooo.", "..o..", "....."}},
+		{"hexagonal", []string{".....", ".oo..", ".ooo.", ".oo..", "....."}},
+	}
+	for _, tt := range tests {
+		n, err := parseNeighborhood(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := rule.WithNeighborhood(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Neighborhood() != n || n.String() != tt.name {
+			t.Errorf("%s: neighborhood %v", tt.name, r.Neighborhood())
+		}
+		if back, err := ParseRule(r.String()); err != nil || back != r {
+			t.Errorf("%s: ParseRule(%q) = %v, %v", tt.name, r.String(), back, err)
+		}
+		l := lifeOf(patternField(seed))
+		l.SetRule(r)
+		l.Next()
+		if got := fieldRows(l.cur); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := parseNeighborhood("neumann"); err == nil {
+		t.Error("parseNeighborhood of unknown name succeeded")
+	}
+	for _, rs := range []string{"B5/S", "B3/S7"} {
+		r, err := ParseRule(rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.WithNeighborhood(VonNeumann); err == nil {
+			t.Errorf("%s: WithNeighborhood(VonNeumann) succeeded", rs)
+		}
+	}
+}
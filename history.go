@@ -0,0 +1,63 @@
+package main
+
+import "errors"
+
+// ErrNoHistory is returned by Prev when there are no previous generations
+// in the history.
+var ErrNoHistory = errors.New("no previous generation in history")
+
+// SetHistory sets the maximum number of previous generations kept for Prev.
+// The history is disabled when depth is 0, which is the default. Older
+// generations beyond depth are dropped.
+func (l *Life) SetHistory(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	l.depth = depth
+	if len(l.history) > depth {
+		l.history = append(l.history[:0], l.history[len(l.history)-depth:]...)
+	}
+}
+
+// Prev restores the previous generation from the history, and sets the
+// generation counter back. It returns ErrNoHistory when the history is
+// empty.
+func (l *Life) Prev() error {
+	if len(l.history) == 0 {
+		return ErrNoHistory
+	}
+	n := len(l.history) - 1
+	l.next = l.cur
+	l.cur = l.history[n]
+	if l.next.h != l.cur.h || l.next.w != l.cur.w {
+		// the field has grown since then.
+		l.next = l.cur.blank()
+	}
+	l.history[n] = nil
+	l.history = l.history[:n]
+	l.gen--
+	return nil
+}
+
+// push adds the field of the generation before current one to the history.
+// The field must not be used by l any more. It returns the field dropped
+// from the history, which is f itself when the history is disabled, or nil.
+func (l *Life) push(f *Field) (dropped *Field) {
+	if l.depth == 0 {
+		return f
+	}
+	if len(l.history) == l.depth {
+		dropped = l.history[0]
+		copy(l.history, l.history[1:])
+		l.history = l.history[:len(l.history)-1]
+	}
+	l.history = append(l.history, f)
+	return dropped
+}
+
+// clearHistory drops the history, e.g. when previous generations no longer
+// match the field. The active region is dropped as well.
+func (l *Life) clearHistory() {
+	l.history = nil
+	l.activeFor = nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+)
+
+// palette is the colors used to render fields: dead cells are white and
+// live cells are black.
+var palette = color.Palette{color.White, color.Black}
+
+// gridPalette is palette with the color of grid lines.
+var gridPalette = append(palette[:len(palette):len(palette)], color.Gray{Y: 0xc0})
+
+// maxImagePixels is the maximum number of pixels of rendered images.
+const maxImagePixels = 1 << 28
+
+// minGridCellSize is the minimum cell size to draw grid lines.
+const minGridCellSize = 4
+
+// image returns the field rendered as paletted image where each cell is
+// drawn as cellSize x cellSize square. When grid is true and cellSize is
+// large enough, 1px grid lines are drawn between cells.
+func (f *Field) image(cellSize int, grid bool) (*image.Paletted, error) {
+	if cellSize <= 0 {
+		return nil, errors.New("cell size must be positive")
+	}
+	if f.w > 0 && f.h > 0 {
+		if cellSize > maxImagePixels/f.w || cellSize > maxImagePixels/f.h ||
+			f.w*cellSize > maxImagePixels/(f.h*cellSize) {
+			return nil, fmt.Errorf("image of %dx%d cells by %d pixels is too large", f.w, f.h, cellSize)
+		}
+	}
+	p := palette
+	if grid && cellSize >= minGridCellSize {
+		p = gridPalette
+	}
+	img := image.NewPaletted(image.Rect(0, 0, f.w*cellSize, f.h*cellSize), p)
+	f.draw(img, cellSize)
+	if len(p) == len(gridPalette) {
+		drawGrid(img, cellSize)
+	}
+	return img, nil
+}
+
+// draw renders the field into img which must be f.w*cellSize x f.h*cellSize.
+func (f *Field) draw(img *image.Paletted, cellSize int) {
+	for y := 0; y < f.h*cellSize; y++ {
+		line := img.Pix[y*img.Stride : y*img.Stride+f.w*cellSize]
+		for x := range line {
+			if f.get(y/cellSize, x/cellSize) {
+				line[x] = 1
+			} else {
+				line[x] = 0
+			}
+		}
+	}
+}
+
+// drawGrid draws grid lines on the last row and column of pixels of each cell.
+func drawGrid(img *image.Paletted, cellSize int) {
+	grid := uint8(len(gridPalette) - 1)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		line := img.Pix[y*img.Stride : y*img.Stride+b.Dx()]
+		for x := range line {
+			if x%cellSize == cellSize-1 || y%cellSize == cellSize-1 {
+				line[x] = grid
+			}
+		}
+	}
+}
+
+// WritePNG writes the field as PNG image where each live cell is drawn as
+// cellSize x cellSize black square on white background.
+func (f *Field) WritePNG(w io.Writer, cellSize int) error {
+	return f.writePNG(w, cellSize, false)
+}
+
+// WritePNGGrid is like WritePNG but draws 1px gray grid lines between cells
+// when cellSize is 4 or more.
+func (f *Field) WritePNGGrid(w io.Writer, cellSize int) error {
+	return f.writePNG(w, cellSize, true)
+}
+
+func (f *Field) writePNG(w io.Writer, cellSize int, grid bool) error {
+	img, err := f.image(cellSize, grid)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// savePNG writes the field as PNG image to the file of path.
+func savePNG(path string, f *Field, cellSize int, grid bool) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f.writePNG(file, cellSize, grid); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
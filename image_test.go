@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestWritePNG(t *testing.T) {
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	black := color.RGBA{0, 0, 0, 0xff}
+	gray := color.RGBA{0xc0, 0xc0, 0xc0, 0xff}
+	glider := patternField(library["glider"])
+	const size = 5
+	for _, grid := range []bool{false, true} {
+		var buf bytes.Buffer
+		write := glider.WritePNG
+		if grid {
+			write = glider.WritePNGGrid
+		}
+		if err := write(&buf, size); err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != 3*size || b.Dy() != 3*size {
+			t.Fatalf("grid %v: image of %dx%d pixels, want %dx%d", grid, b.Dx(), b.Dy(), 3*size, 3*size)
+		}
+		at := func(x, y int) color.RGBA {
+			return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+		}
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				want := white
+				if glider.Alive(i, j) {
+					want = black
+				}
+				if got := at(j*size+size/2, i*size+size/2); got != want {
+					t.Errorf("grid %v: center of cell (%d, %d) is %v, want %v", grid, i, j, got, want)
+				}
+				// the last row and column of pixels of each cell are grid lines.
+				if grid {
+					want = gray
+				}
+				if got := at(j*size+size-1, i*size+size/2); got != want {
+					t.Errorf("grid %v: right edge of cell (%d, %d) is %v, want %v", grid, i, j, got, want)
+				}
+				if got := at(j*size+size/2, i*size+size-1); got != want {
+					t.Errorf("grid %v: bottom edge of cell (%d, %d) is %v, want %v", grid, i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestWritePNGGridSmallCells(t *testing.T) {
+	var buf bytes.Buffer
+	if err := patternField([]string{"o."}).WritePNGGrid(&buf, 3); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// no grid lines for cells smaller than minGridCellSize.
+	if got := color.GrayModel.Convert(img.At(2, 2)).(color.Gray); got.Y != 0 {
+		t.Errorf("corner of live cell is %v, want black", got)
+	}
+}
+
+func TestWritePNGError(t *testing.T) {
+	tests := []struct {
+		f        *Field
+		cellSize int
+		want     string
+	}{
+		{NewField(3, 3), 0, "must be positive"},
+		{NewField(3, 3), -1, "must be positive"},
+		{&Field{h: 1 << 20, w: 1 << 20}, 1, "too large"},
+		{&Field{h: 1, w: 1 << 20}, 1 << 10, "too large"},
+		{&Field{h: 2, w: 2}, 1 << 62, "too large"},
+	}
+	for _, tt := range tests {
+		err := tt.f.WritePNG(&bytes.Buffer{}, tt.cellSize)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%dx%d by %d: got %v, want error containing %q", tt.f.w, tt.f.h, tt.cellSize, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// fieldJSON is JSON representation of Field. Each row of cells is encoded
+// as string of '.' for dead cell and 'o' for live cell.
+type fieldJSON struct {
+	Width    int      `json:"width"`
+	Height   int      `json:"height"`
+	Rule     string   `json:"rule,omitempty"`
+	Topology string   `json:"topology,omitempty"`
+	Cells    []string `json:"cells"`
+	Dying    []int    `json:"dying,omitempty"` // dying states of Generations rules in row-major order
+}
+
+// lifeJSON is JSON representation of Life.
+type lifeJSON struct {
+	Generation int `json:"generation"`
+	fieldJSON
+}
+
+func (f *Field) toJSON() fieldJSON {
+	cells := make([]string, f.h)
+	buf := make([]byte, f.w)
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			if f.get(i, j) {
+				buf[j] = 'o'
+			} else {
+				buf[j] = '.'
+			}
+		}
+		cells[i] = string(buf)
+	}
+	fj := fieldJSON{
+		Width:    f.w,
+		Height:   f.h,
+		Rule:     f.rule.String(),
+		Topology: f.topo.String(),
+		Cells:    cells,
+	}
+	if f.dying != nil {
+		fj.Dying = make([]int, 0, f.h*f.w)
+		for _, row := range f.dying {
+			for _, d := range row {
+				fj.Dying = append(fj.Dying, int(d))
+			}
+		}
+	}
+	return fj
+}
+
+func (fj *fieldJSON) toField() (*Field, error) {
+	if fj.Width <= 0 || fj.Height <= 0 {
+		return nil, fmt.Errorf("invalid field size %dx%d", fj.Width, fj.Height)
+	}
+	if len(fj.Cells) != fj.Height {
+		return nil, fmt.Errorf("got %d rows, want %d", len(fj.Cells), fj.Height)
+	}
+	f := NewField(fj.Height, fj.Width)
+	for i, r := range fj.Cells {
+		if len(r) != fj.Width {
+			return nil, fmt.Errorf("row %d: got %d cells, want %d", i, len(r), fj.Width)
+		}
+		for j := 0; j < len(r); j++ {
+			switch r[j] {
+			case '.':
+			case 'o':
+				f.put(i, j, true)
+			default:
+				return nil, fmt.Errorf("row %d: unexpected character %q", i, r[j])
+			}
+		}
+	}
+	if fj.Rule != "" {
+		rule, err := ParseRule(fj.Rule)
+		if err != nil {
+			return nil, err
+		}
+		f.SetRule(rule)
+	}
+	if fj.Dying != nil {
+		if f.dying == nil {
+			return nil, fmt.Errorf("dying states for rule %v without them", f.rule)
+		}
+		if len(fj.Dying) != fj.Height*fj.Width {
+			return nil, fmt.Errorf("got %d dying states, want %d", len(fj.Dying), fj.Height*fj.Width)
+		}
+		for k, d := range fj.Dying {
+			if d < 0 || d > f.rule.states-2 {
+				return nil, fmt.Errorf("invalid dying state %d of cell %d", d, k)
+			}
+			f.dying[k/fj.Width][k%fj.Width] = uint8(d)
+		}
+	}
+	if fj.Topology != "" {
+		topo, err := parseTopology(fj.Topology)
+		if err != nil {
+			return nil, err
+		}
+		f.topo = topo
+	}
+	return f, nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f *Field) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.toJSON())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *Field) UnmarshalJSON(data []byte) error {
+	var fj fieldJSON
+	if err := json.Unmarshal(data, &fj); err != nil {
+		return err
+	}
+	nf, err := fj.toField()
+	if err != nil {
+		return err
+	}
+	*f = *nf
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l *Life) MarshalJSON() ([]byte, error) {
+	return json.Marshal(lifeJSON{Generation: l.gen, fieldJSON: l.cur.toJSON()})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *Life) UnmarshalJSON(data []byte) error {
+	var lj lifeJSON
+	if err := json.Unmarshal(data, &lj); err != nil {
+		return err
+	}
+	cur, err := lj.toField()
+	if err != nil {
+		return err
+	}
+	*l = Life{cur: cur, next: cur.blank(), gen: lj.Generation}
+	return nil
+}
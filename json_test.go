@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// equalStates reports whether the states of all cells of a and b are equal.
+func equalStates(a, b *Field) bool {
+	if a.h != b.h || a.w != b.w {
+		return false
+	}
+	for i := 0; i < a.h; i++ {
+		for j := 0; j < a.w; j++ {
+			if a.State(i, j) != b.State(i, j) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestLifeJSONRoundTrip(t *testing.T) {
+	for _, rule := range []string{"B3/S23", "B2/S/C3", "B2/S345/C4"} {
+		r, err := ParseRule(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := NewRandomLife(20, 30, 0.3, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.SetRule(r)
+		l.SetTopology(Fixed)
+		l.Run(5)
+
+		data, err := json.Marshal(l)
+		if err != nil {
+			t.Fatalf("%v: Marshal: %v", rule, err)
+		}
+		var got Life
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%v: Unmarshal: %v", rule, err)
+		}
+		for g := 0; g <= 10; g++ {
+			if !equalStates(got.cur, l.cur) {
+				t.Fatalf("%v: generation %d differs after round trip", rule, l.gen)
+			}
+			l.Next()
+			got.Next()
+		}
+	}
+}
+
+func TestFieldUnmarshalJSONError(t *testing.T) {
+	for _, data := range []string{
+		`{"width":2,"height":1,"cells":["ox"]}`,
+		`{"width":2,"height":1,"cells":["o"]}`,
+		`{"width":2,"height":1,"cells":["o."],"dying":[0,1]}`,
+		`{"width":2,"height":1,"rule":"B2/S/C3","cells":["o."],"dying":[0]}`,
+		`{"width":2,"height":1,"rule":"B2/S/C3","cells":["o."],"dying":[0,2]}`,
+	} {
+		var f Field
+		if err := json.Unmarshal([]byte(data), &f); err == nil {
+			t.Errorf("Unmarshal(%s) = nil, want error", data)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// life105Header is the first line of Life 1.05 pattern.
+const life105Header = "#Life 1.05"
+
+// NewLifeFromLife105 create new lifegame buffer from Life 1.05 pattern.
+// All "#P x y" blocks are placed onto one field sized to their bounding box,
+// and live cells of overlapping blocks are merged. The rule of "#R" line
+// is applied, and Conway's rule is used without it.
+// See details of the format.
+//
+//	http://www.conwaylife.com/wiki/Life_1.05
+func NewLifeFromLife105(r io.Reader) (*Life, error) {
+	s := bufio.NewScanner(r)
+	header := false
+	var cells [][2]int // pairs of row and column
+	minR, minC, maxR, maxC := 0, 0, 0, 0
+	x, y := 0, 0 // top left corner of current block
+	rule := Conway
+	lineno := 0
+	for s.Scan() {
+		lineno++
+		line := strings.TrimSpace(strings.TrimPrefix(s.Text(), utf8BOM))
+		if line == "" {
+			continue
+		}
+		if !header {
+			if line != life105Header {
+				return nil, fmt.Errorf("life 1.05: line %d: header %q not found", lineno, life105Header)
+			}
+			header = true
+			continue
+		}
+		if strings.HasPrefix(line, "#P") {
+			fields := strings.Fields(line[2:])
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("life 1.05: line %d: malformed block position %q", lineno, line)
+			}
+			var err error
+			if x, err = strconv.Atoi(fields[0]); err != nil {
+				return nil, fmt.Errorf("life 1.05: line %d: %v", lineno, err)
+			}
+			if y, err = strconv.Atoi(fields[1]); err != nil {
+				return nil, fmt.Errorf("life 1.05: line %d: %v", lineno, err)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#R") {
+			// rules are written in S/B notation such as "#R 23/3".
+			var err error
+			if rule, err = ParseRule(strings.TrimSpace(line[2:])); err != nil {
+				return nil, fmt.Errorf("life 1.05: line %d: %v", lineno, err)
+			}
+			continue
+		}
+		if line[0] == '#' {
+			// #D description and #N for the normal rule of Conway's.
+			continue
+		}
+		for i, c := range line {
+			switch c {
+			case '.':
+			case '*':
+				row, col := y, x+i
+				if len(cells) == 0 || row < minR {
+					minR = row
+				}
+				if len(cells) == 0 || row > maxR {
+					maxR = row
+				}
+				if len(cells) == 0 || col < minC {
+					minC = col
+				}
+				if len(cells) == 0 || col > maxC {
+					maxC = col
+				}
+				cells = append(cells, [2]int{row, col})
+			default:
+				return nil, fmt.Errorf("life 1.05: line %d: unexpected character %q", lineno, c)
+			}
+		}
+		y++
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(cells) == 0 {
+		return nil, errors.New("life 1.05: no live cells")
+	}
+	l, err := newLifeFromCoords(maxR-minR+1, maxC-minC+1, cells, minR, minC)
+	if err != nil {
+		return nil, err
+	}
+	l.SetRule(rule)
+	return l, nil
+}
+
+// newLifeFromLif create new lifegame buffer from either Life 1.05 or
+// Life 1.06 pattern, as both of them use .lif extension.
+func newLifeFromLif(r io.Reader) (*Life, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(sniffLen)
+	head = bytes.TrimLeftFunc(bytes.TrimPrefix(head, []byte(utf8BOM)), unicode.IsSpace)
+	if bytes.HasPrefix(head, []byte(life106Header)) {
+		return NewLifeFromLife106(br)
+	}
+	return NewLifeFromLife105(br)
+}
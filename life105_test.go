@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewLifeFromLife105Rule(t *testing.T) {
+	tests := []struct {
+		src  string
+		rule string
+	}{
+		{"#Life 1.05\n#D glider\n#P -1 -1\n.*.\n..*\n***\n", "B3/S23"},
+		{"#Life 1.05\n#N\n#P -1 -1\n.*.\n..*\n***\n", "B3/S23"},
+		{"#Life 1.05\n#R 23/36\n#P -1 -1\n.*.\n..*\n***\n", "B36/S23"},
+		{"#Life 1.05\n#R 125/36\n#P 0 0\n.*.\n..*\n***\n", "B36/S125"},
+		{"#Life 1.05\n#P 0 0\n.*.\n..*\n***\n#R B2/S\n", "B2/S"},
+	}
+	for _, tt := range tests {
+		l, err := NewLifeFromLife105(strings.NewReader(tt.src))
+		if err != nil {
+			t.Errorf("%q: %v", tt.src, err)
+			continue
+		}
+		if got := l.Rule().String(); got != tt.rule {
+			t.Errorf("%q: rule %s, want %s", tt.src, got, tt.rule)
+		}
+		if got, want := fieldRows(l.cur), library["glider"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %q, want %q", tt.src, got, want)
+		}
+	}
+}
+
+func TestNewLifeFromLife105RuleError(t *testing.T) {
+	_, err := NewLifeFromLife105(strings.NewReader("#Life 1.05\n#R 9/3\n#P 0 0\n*\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("got %v, want error of line 2", err)
+	}
+}
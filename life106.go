@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// life106Header is the first line of Life 1.06 pattern.
+const life106Header = "#Life 1.06"
+
+// NewLifeFromLife106 create new lifegame buffer from Life 1.06 pattern.
+// The field is sized to the bounding box of live cells, and the top left
+// corner of the box is mapped to (0, 0). A pattern without live cells, such
+// as WriteLife106 writes for an empty field, is loaded as a dead cell.
+// See details of the format.
+//
+//	http://www.conwaylife.com/wiki/Life_1.06
+func NewLifeFromLife106(r io.Reader) (*Life, error) {
+	return NewLifeFromLife106Margin(r, 0)
+}
+
+// NewLifeFromLife106Margin is like NewLifeFromLife106 but surrounds the
+// bounding box of live cells with margin dead cells on each side.
+func NewLifeFromLife106Margin(r io.Reader, margin int) (*Life, error) {
+	if margin < 0 {
+		return nil, errors.New("life 1.06: negative margin")
+	}
+	cells, minR, minC, maxR, maxC, err := readLife106(r)
+	if err != nil {
+		return nil, err
+	}
+	h, w := maxR-minR+1+2*margin, maxC-minC+1+2*margin
+	return newLifeFromCoords(h, w, cells, minR-margin, minC-margin)
+}
+
+// NewLifeFromLife106Size create new lifegame buffer of h x w cells from
+// Life 1.06 pattern. The top left corner of the bounding box of live cells
+// is mapped to (0, 0), and it is an error if the pattern does not fit.
+func NewLifeFromLife106Size(r io.Reader, h, w int) (*Life, error) {
+	cells, minR, minC, maxR, maxC, err := readLife106(r)
+	if err != nil {
+		return nil, err
+	}
+	if maxR-minR+1 > h || maxC-minC+1 > w {
+		return nil, fmt.Errorf("life 1.06: pattern of %dx%d cells does not fit in %dx%d field",
+			maxR-minR+1, maxC-minC+1, h, w)
+	}
+	return newLifeFromCoords(h, w, cells, minR, minC)
+}
+
+// newLifeFromCoords builds h x w lifegame buffer with cells translated by
+// (-offR, -offC). The size is limited to maxPatternCells, so that a few
+// cells far apart cannot exhaust memory.
+func newLifeFromCoords(h, w int, cells [][2]int, offR, offC int) (*Life, error) {
+	if h <= 0 || w <= 0 || h > maxPatternCells/w {
+		return nil, fmt.Errorf("pattern of %d x %d cells exceeds %d cells", w, h, maxPatternCells)
+	}
+	init := make([][]bool, h)
+	for i := range init {
+		init[i] = make([]bool, w)
+	}
+	for _, c := range cells {
+		init[c[0]-offR][c[1]-offC] = true
+	}
+	return NewLife(h, w, init)
+}
+
+// readLife106 reads live cells as pairs of row and column, and returns them
+// with their bounding box, which is the cell at (0, 0) when there are no
+// live cells. Duplicate cells are kept as they are.
+func readLife106(r io.Reader) (cells [][2]int, minR, minC, maxR, maxC int, err error) {
+	s := bufio.NewScanner(r)
+	header := false
+	lineno := 0
+	for s.Scan() {
+		lineno++
+		line := strings.TrimSpace(strings.TrimPrefix(s.Text(), utf8BOM))
+		if line == "" {
+			continue
+		}
+		if !header {
+			if line != life106Header {
+				return nil, 0, 0, 0, 0, fmt.Errorf("life 1.06: line %d: header %q not found", lineno, life106Header)
+			}
+			header = true
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, 0, 0, 0, 0, fmt.Errorf("life 1.06: line %d: want 2 integers, got %q", lineno, line)
+		}
+		x, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, 0, 0, 0, 0, fmt.Errorf("life 1.06: line %d: %v", lineno, err)
+		}
+		y, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, 0, 0, 0, 0, fmt.Errorf("life 1.06: line %d: %v", lineno, err)
+		}
+		if len(cells) == 0 || y < minR {
+			minR = y
+		}
+		if len(cells) == 0 || y > maxR {
+			maxR = y
+		}
+		if len(cells) == 0 || x < minC {
+			minC = x
+		}
+		if len(cells) == 0 || x > maxC {
+			maxC = x
+		}
+		cells = append(cells, [2]int{y, x})
+	}
+	if err := s.Err(); err != nil {
+		return nil, 0, 0, 0, 0, err
+	}
+	if !header {
+		return nil, 0, 0, 0, 0, fmt.Errorf("life 1.06: header %q not found", life106Header)
+	}
+	return cells, minR, minC, maxR, maxC, nil
+}
+
+// WriteLife106 writes live cells of the field as Life 1.06 pattern.
+// Coordinates are written in row-major order so that the output is deterministic.
+func (f *Field) WriteLife106(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, life106Header)
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			if f.get(i, j) {
+				fmt.Fprintf(bw, "%d %d\n", j, i)
+			}
+		}
+	}
+	return bw.Flush()
+}
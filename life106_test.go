@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewLifeFromLife106(t *testing.T) {
+	src := "#Life 1.06\n1 -1\n2 0\n0 1\n1 1\n2 1\n"
+	l, err := NewLifeFromLife106(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fieldRows(l.cur), library["glider"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	l, err = NewLifeFromLife106Margin(strings.NewReader(src), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fieldRows(l.cur), []string{".....", "..o..", "...o.", ".ooo.", "....."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("margin 1: got %q, want %q", got, want)
+	}
+}
+
+func TestNewLifeFromCoordsTooLarge(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func() (*Life, error)
+	}{
+		{"life 1.06", func() (*Life, error) {
+			return NewLifeFromLife106(strings.NewReader("#Life 1.06\n0 0\n100000 100000\n"))
+		}},
+		{"life 1.06 far negative", func() (*Life, error) {
+			return NewLifeFromLife106(strings.NewReader("#Life 1.06\n-9223372036854775808 0\n9223372036854775807 0\n"))
+		}},
+		{"life 1.06 margin", func() (*Life, error) {
+			return NewLifeFromLife106Margin(strings.NewReader("#Life 1.06\n0 0\n"), 2048)
+		}},
+		{"life 1.06 size", func() (*Life, error) {
+			return NewLifeFromLife106Size(strings.NewReader("#Life 1.06\n0 0\n"), 100000, 100000)
+		}},
+		{"life 1.05", func() (*Life, error) {
+			return NewLifeFromLife105(strings.NewReader("#Life 1.05\n#P 0 0\n*\n#P 100000 100000\n*\n"))
+		}},
+	}
+	for _, tt := range tests {
+		_, err := tt.parse()
+		if err == nil || !strings.Contains(err.Error(), "exceeds") {
+			t.Errorf("%s: got %v, want error of too large pattern", tt.name, err)
+		}
+	}
+}
+
+func TestLife106Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewField(3, 4).WriteLife106(&buf); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLifeFromLife106(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("%q: %v", buf.String(), err)
+	}
+	if got, want := fieldRows(l.cur), []string{"."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	l, err = NewLifeFromLife106Margin(bytes.NewReader(buf.Bytes()), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fieldRows(l.cur), []string{"...", "...", "..."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("margin 1: got %q, want %q", got, want)
+	}
+	l, err = NewLifeFromLife106Size(bytes.NewReader(buf.Bytes()), 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.cur.h != 3 || l.cur.w != 4 || l.Population() != 0 {
+		t.Errorf("size 3x4: got %dx%d field of %d cells", l.cur.h, l.cur.w, l.Population())
+	}
+	for _, src := range []string{"", "\n\n", "0 0\n"} {
+		if _, err := NewLifeFromLife106(strings.NewReader(src)); err == nil || !strings.Contains(err.Error(), "header") {
+			t.Errorf("%q: got %v, want error of missing header", src, err)
+		}
+	}
+}
+
+func TestLifHeaderBOM(t *testing.T) {
+	glider106 := "#Life 1.06\n1 -1\n2 0\n0 1\n1 1\n2 1\n"
+	glider105 := "#Life 1.05\n#P 0 0\n.*.\n..*\n***\n"
+	for _, prefix := range []string{"\ufeff", "\n", "  \r\n\t", "\ufeff \n"} {
+		for _, src := range []string{glider106, glider105} {
+			for _, parse := range []func(io.Reader) (*Life, error){newLifeFromLif, NewLifeFromReader} {
+				l, err := parse(strings.NewReader(prefix + src))
+				if err != nil {
+					t.Errorf("%q: %v", prefix+src, err)
+					continue
+				}
+				if got, want := fieldRows(l.cur), library["glider"]; !reflect.DeepEqual(got, want) {
+					t.Errorf("%q: got %q, want %q", prefix+src, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxRadius is the maximum radius of neighborhoods of Larger than Life rules.
+const maxRadius = 500
+
+// Radius returns the radius of the neighborhood of the rule, which is 1
+// for rules other than Larger than Life rules.
+func (r Rule) Radius() int {
+	if r.radius == 0 {
+		return 1
+	}
+	return r.radius
+}
+
+// LargerThanLife returns the Larger than Life rule counting live cells in
+// Moore neighborhood of radius, except the cell itself. A dead cell is born
+// when the count is in [bmin, bmax], and a live cell survives when it is in
+// [smin, smax]. LargerThanLife(1, 3, 3, 2, 3) is the same as Conway's.
+func LargerThanLife(radius, bmin, bmax, smin, smax int) (Rule, error) {
+	r := Rule{radius: radius, bmin: bmin, bmax: bmax, smin: smin, smax: smax}
+	if err := r.validateLtL(); err != nil {
+		return Rule{}, fmt.Errorf("rule %v: %v", r, err)
+	}
+	return r, nil
+}
+
+// validateLtL reports an error if the radius or the ranges of counts are
+// out of range.
+func (r Rule) validateLtL() error {
+	if r.radius < 1 || r.radius > maxRadius {
+		return fmt.Errorf("radius must be 1 to %d", maxRadius)
+	}
+	if r.bmin < 0 || r.bmin > r.bmax || r.smin < 0 || r.smin > r.smax {
+		return errors.New("invalid range of counts")
+	}
+	if n := (2*r.radius + 1) * (2*r.radius + 1); r.bmax > n || r.smax > n {
+		return fmt.Errorf("counts exceed %d cells of the neighborhood", n)
+	}
+	return nil
+}
+
+// parseLtL parses Larger than Life rule string s such as
+// "R5,C0,M1,S34..58,B34..45,NM". R, B and S are required; C defaults to
+// two states, M to 0, which excludes the cell itself from the counts, and
+// only Moore neighborhood NM is supported.
+func parseLtL(s string) (Rule, error) {
+	var r Rule
+	var hasB, hasS bool
+	for _, tok := range strings.Split(strings.ToUpper(strings.TrimSpace(s)), ",") {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			return Rule{}, fmt.Errorf("rule %q: empty part", s)
+		}
+		var err error
+		switch tok[0] {
+		case 'R':
+			r.radius, err = strconv.Atoi(tok[1:])
+		case 'C':
+			r.states, err = strconv.Atoi(tok[1:])
+			if err == nil && (r.states < 0 || r.states > maxStates) {
+				err = fmt.Errorf("number of states must be 0 to %d", maxStates)
+			}
+		case 'M':
+			switch tok[1:] {
+			case "0":
+				r.middle = false
+			case "1":
+				r.middle = true
+			default:
+				err = fmt.Errorf("invalid %q", tok)
+			}
+		case 'B':
+			r.bmin, r.bmax, err = parseRange(tok[1:])
+			hasB = true
+		case 'S':
+			r.smin, r.smax, err = parseRange(tok[1:])
+			hasS = true
+		case 'N':
+			if tok != "NM" {
+				err = fmt.Errorf("neighborhood %q is not supported", tok[1:])
+			}
+		default:
+			err = fmt.Errorf("unknown part %q", tok)
+		}
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %v", s, err)
+		}
+	}
+	if r.radius == 0 || !hasB || !hasS {
+		return Rule{}, fmt.Errorf("rule %q: want R..,B..,S.. notation", s)
+	}
+	if err := r.validateLtL(); err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %v", s, err)
+	}
+	if r.states <= 2 {
+		r.states = 0
+	}
+	return r, nil
+}
+
+// parseRange parses range of counts such as "34..45", or "3" for "3..3".
+func parseRange(s string) (lo, hi int, err error) {
+	l, h := s, s
+	if i := strings.Index(s, ".."); i >= 0 {
+		l, h = s[:i], s[i+2:]
+	}
+	if lo, err = strconv.Atoi(l); err != nil {
+		return 0, 0, err
+	}
+	if hi, err = strconv.Atoi(h); err != nil {
+		return 0, 0, err
+	}
+	if lo < 0 || lo > hi {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	return lo, hi, nil
+}
+
+// ltlString returns the Larger than Life rule in the notation parseLtL reads.
+func (r Rule) ltlString() string {
+	var buf bytes.Buffer
+	m := 0
+	if r.middle {
+		m = 1
+	}
+	fmt.Fprintf(&buf, "R%d,C%d,M%d,S%d..%d,B%d..%d,NM", r.radius, r.states, m, r.smin, r.smax, r.bmin, r.bmax)
+	return buf.String()
+}
+
+// prefixSums returns the numbers of live cells in the rectangles from (0, 0)
+// to (i-1, j-1) of the tile of the field, which is repeated infinitely
+// outside of the field. The tile is the field itself, or 2h x 2w cells
+// of the field and its reflections in Mirror topology.
+func (f *Field) prefixSums() [][]int {
+	h, w := f.h, f.w
+	if f.topo == Mirror {
+		h, w = 2*h, 2*w
+	}
+	coord := topologyCoords[f.topo]
+	sums := make([][]int, h+1)
+	sums[0] = make([]int, w+1)
+	for i := 0; i < h; i++ {
+		sums[i+1] = make([]int, w+1)
+		r, _ := coord(i, f.h)
+		row := 0
+		for j := 0; j < w; j++ {
+			if c, _ := coord(j, f.w); f.get(r, c) {
+				row++
+			}
+			sums[i+1][j+1] = sums[i][j+1] + row
+		}
+	}
+	return sums
+}
+
+// rectSum returns the number of live cells in rows from r0 to r1-1 and
+// columns from c0 to c1-1 with the prefix sums of f. Outside of the field
+// is dead in Fixed topology, and repeats the tile of prefixSums otherwise.
+func (f *Field) rectSum(sums [][]int, r0, c0, r1, c1 int) int {
+	if f.topo != Fixed {
+		return wrapSum(sums, r1, c1) - wrapSum(sums, r0, c1) - wrapSum(sums, r1, c0) + wrapSum(sums, r0, c0)
+	}
+	r0, r1 = clip(r0, f.h), clip(r1, f.h)
+	c0, c1 = clip(c0, f.w), clip(c1, f.w)
+	return sums[r1][c1] - sums[r0][c1] - sums[r1][c0] + sums[r0][c0]
+}
+
+// wrapSum returns the number of live cells in rows from 0 to r-1 and
+// columns from 0 to c-1 of the tile of sums repeated infinitely. r and c
+// may be negative.
+func wrapSum(sums [][]int, r, c int) int {
+	h, w := len(sums)-1, len(sums[0])-1
+	qr, ar := floorDiv(r, h)
+	qc, ac := floorDiv(c, w)
+	return qr*qc*sums[h][w] + qr*sums[h][ac] + qc*sums[ar][w] + sums[ar][ac]
+}
+
+// floorDiv returns the quotient rounded down and the non-negative remainder.
+func floorDiv(a, n int) (q, r int) {
+	q, r = a/n, a%n
+	if r < 0 {
+		q, r = q-1, r+n
+	}
+	return q, r
+}
+
+// clip returns x limited to [0, n].
+func clip(x, n int) int {
+	if x < 0 {
+		return 0
+	}
+	if x > n {
+		return n
+	}
+	return x
+}
+
+// ltlCount returns the number of live cells in the neighborhood of the
+// cell of r & c of Larger than Life rule with the prefix sums of f. Cells
+// of the neighborhood are counted one by one when sums is nil, which is
+// cheaper than building the prefix sums for a single cell.
+func (f *Field) ltlCount(sums [][]int, r, c int) int {
+	d := f.rule.radius
+	n := 0
+	if sums == nil {
+		for i := r - d; i <= r+d; i++ {
+			for j := c - d; j <= c+d; j++ {
+				if f.Alive(i, j) {
+					n++
+				}
+			}
+		}
+	} else {
+		n = f.rectSum(sums, r-d, c-d, r+d+1, c+d+1)
+	}
+	if !f.rule.middle && f.get(r, c) {
+		n--
+	}
+	return n
+}
+
+// ltlNext returns whether the cell of r & c will be alive in next
+// generation of Larger than Life rule with the prefix sums of f, or nil
+// sums as ltlCount takes.
+func (f *Field) ltlNext(sums [][]int, r, c int) bool {
+	if f.dying != nil && f.dying[r][c] > 0 {
+		return false
+	}
+	n := f.ltlCount(sums, r, c)
+	if f.get(r, c) {
+		return n >= f.rule.smin && n <= f.rule.smax
+	}
+	return n >= f.rule.bmin && n <= f.rule.bmax
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLtLRadius1IsConway(t *testing.T) {
+	ltl, err := ParseRule("R1,C0,M0,S2..3,B3..3,NM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, topo := range []Topology{Torus, Fixed, Mirror} {
+		a := lifeOf(randomField(40, 50, 0.3, 1, false))
+		b := lifeOf(randomField(40, 50, 0.3, 1, false))
+		a.SetTopology(topo)
+		b.SetTopology(topo)
+		b.SetRule(ltl)
+		for g := 0; g < 50; g++ {
+			if !a.cur.Equal(b.cur) {
+				t.Fatalf("%v: generation %d differs", topo, g)
+			}
+			a.Next()
+			b.Next()
+		}
+	}
+}
+
+func TestLtLLiveNeighbors(t *testing.T) {
+	rule, err := ParseRule("R2,C0,M0,S2..3,B3..3,NM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	corner := []string{"o.....", "......", "......", "......", "......"}
+	tests := []struct {
+		topo Topology
+		want []string // neighbor counts of each cell
+	}{
+		{Torus, []string{"011011", "111011", "111011", "111011", "111011"}},
+		{Fixed, []string{"011000", "111000", "111000", "000000", "000000"}},
+		{Mirror, []string{"342000", "442000", "221000", "000000", "000000"}},
+	}
+	for _, tt := range tests {
+		f := patternField(corner)
+		f.SetRule(rule)
+		f.SetTopology(tt.topo)
+		if got := neighborCounts(f); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %q, want %q", tt.topo, got, tt.want)
+		}
+	}
+
+	// cells 2 away from the center are in the neighborhood of radius 2.
+	f := NewField(11, 11)
+	f.SetRule(rule)
+	f.Set(3, 3, true)
+	f.Set(5, 7, true)
+	f.Set(2, 5, true) // 3 away
+	for _, topo := range []Topology{Torus, Fixed, Mirror} {
+		f.SetTopology(topo)
+		if n := f.LiveNeighbors(5, 5); n != 2 {
+			t.Errorf("%v: LiveNeighbors(5, 5) = %d, want 2", topo, n)
+		}
+	}
+}
+
+func TestLtLNextGen(t *testing.T) {
+	for _, rs := range []string{
+		"R1,C0,M0,S2..3,B3..3,NM",
+		"R2,C0,M1,S7..12,B7..9,NM",
+		"R5,C0,M1,S34..58,B34..45,NM",
+		"R3,C4,M0,S10..20,B9..14,NM",
+	} {
+		rule, err := ParseRule(rs)
+		if err != nil {
+			t.Fatal(rs, err)
+		}
+		for _, topo := range []Topology{Torus, Fixed, Mirror} {
+			// 7 x 9 fields are smaller than neighborhoods of radius 5.
+			for _, size := range [][2]int{{7, 9}, {30, 41}} {
+				l := lifeOf(randomField(size[0], size[1], 0.4, 2, false))
+				l.SetRule(rule)
+				l.SetTopology(topo)
+				for g := 0; g < 10; g++ {
+					f := l.cur
+					want := make([][]bool, f.h)
+					for i := range want {
+						want[i] = make([]bool, f.w)
+						for j := range want[i] {
+							want[i][j] = f.NextGen(i, j)
+						}
+					}
+					l.Next()
+					for i := range want {
+						for j := range want[i] {
+							if got := l.cur.Alive(i, j); got != want[i][j] {
+								t.Fatalf("%s %v %dx%d generation %d: cell (%d, %d) NextGen %v, Next %v",
+									rs, topo, size[0], size[1], g, i, j, want[i][j], got)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkLtLNextGen(b *testing.B) {
+	rule, err := ParseRule("R5,C0,M1,S34..58,B34..45,NM")
+	if err != nil {
+		b.Fatal(err)
+	}
+	f := randomField(256, 256, 0.4, 1, false)
+	f.SetRule(rule)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.NextGen(i%f.h, i/f.h%f.w)
+	}
+}
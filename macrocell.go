@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// macrocellHeader is the prefix of the first line of macrocell pattern.
+const macrocellHeader = "[M2]"
+
+// maxMacrocellLevel is the maximum level of the root node of macrocell
+// pattern, which limits the expanded pattern to 4096 x 4096 cells.
+const maxMacrocellLevel = 12
+
+// mcNode is a node of macrocell quadtree. Nodes of level 3 are leaves of
+// 8 x 8 cells, and others refer their children by index.
+type mcNode struct {
+	level int
+	leaf  [8]uint8 // rows of leaf node, bit i is the cell of column i
+	kids  [4]int   // nw, ne, sw, se; 0 is empty node
+}
+
+// NewLifeFromMacrocell create new lifegame buffer from Golly's macrocell
+// pattern. The field is sized to the bounding box of live cells.
+// Patterns larger than 4096 x 4096 cells are rejected.
+// See details of the format.
+//
+//	http://www.conwaylife.com/wiki/Macrocell
+func NewLifeFromMacrocell(r io.Reader) (*Life, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	nodes := []mcNode{{}} // index 0 is the empty node
+	rule := Conway
+	gen := 0
+	lineno := 0
+	for s.Scan() {
+		lineno++
+		line := strings.TrimSpace(s.Text())
+		if lineno == 1 {
+			if !strings.HasPrefix(line, macrocellHeader) {
+				return nil, fmt.Errorf("macrocell: header %q not found", macrocellHeader)
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		if line[0] == '#' {
+			var err error
+			switch {
+			case strings.HasPrefix(line, "#R"):
+				rule, err = ParseRule(strings.TrimSpace(line[2:]))
+			case strings.HasPrefix(line, "#G"):
+				gen, err = strconv.Atoi(strings.TrimSpace(line[2:]))
+			}
+			if err != nil {
+				return nil, fmt.Errorf("macrocell: line %d: %v", lineno, err)
+			}
+			continue
+		}
+		var n mcNode
+		var err error
+		if line[0] == '.' || line[0] == '*' || line[0] == '$' {
+			n, err = parseMacrocellLeaf(line)
+		} else {
+			n, err = parseMacrocellNode(line, nodes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("macrocell: line %d: %v", lineno, err)
+		}
+		nodes = append(nodes, n)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(nodes) == 1 {
+		return nil, errors.New("macrocell: no nodes")
+	}
+
+	root := nodes[len(nodes)-1]
+	if root.level > maxMacrocellLevel {
+		return nil, fmt.Errorf("macrocell: pattern of 2^%d cells square exceeds 2^%d", root.level, maxMacrocellLevel)
+	}
+	size := 1 << uint(root.level)
+	full := make([][]bool, size)
+	for i := range full {
+		full[i] = make([]bool, size)
+	}
+	expandMacrocell(nodes, len(nodes)-1, full, 0, 0)
+
+	minR, minC, maxR, maxC := size, size, -1, -1
+	for i, row := range full {
+		for j, c := range row {
+			if !c {
+				continue
+			}
+			if i < minR {
+				minR = i
+			}
+			if i > maxR {
+				maxR = i
+			}
+			if j < minC {
+				minC = j
+			}
+			if j > maxC {
+				maxC = j
+			}
+		}
+	}
+	if maxR < 0 {
+		return nil, errors.New("macrocell: no live cells")
+	}
+	init := full[minR : maxR+1]
+	for i := range init {
+		init[i] = init[i][minC : maxC+1]
+	}
+	l, err := NewLife(maxR-minR+1, maxC-minC+1, init)
+	if err != nil {
+		return nil, err
+	}
+	l.SetRule(rule)
+	l.gen = gen
+	return l, nil
+}
+
+// parseMacrocellLeaf parses 8 x 8 leaf such as "$.**$*" where '$' ends rows.
+func parseMacrocellLeaf(line string) (mcNode, error) {
+	n := mcNode{level: 3}
+	row, col := 0, 0
+	for _, c := range line {
+		switch c {
+		case '.', '*':
+			if row >= 8 || col >= 8 {
+				return mcNode{}, errors.New("leaf exceeds 8x8 cells")
+			}
+			if c == '*' {
+				n.leaf[row] |= 1 << uint(col)
+			}
+			col++
+		case '$':
+			row++
+			col = 0
+		default:
+			return mcNode{}, fmt.Errorf("unexpected character %q in leaf", c)
+		}
+	}
+	return n, nil
+}
+
+// parseMacrocellNode parses "level nw ne sw se" referring to nodes defined before.
+func parseMacrocellNode(line string, nodes []mcNode) (mcNode, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return mcNode{}, fmt.Errorf("malformed node %q", line)
+	}
+	var n mcNode
+	var err error
+	if n.level, err = strconv.Atoi(fields[0]); err != nil {
+		return mcNode{}, err
+	}
+	if n.level < 4 {
+		// level 1 nodes are used only by multi-state rules.
+		return mcNode{}, fmt.Errorf("unsupported node level %d", n.level)
+	}
+	for i := range n.kids {
+		k, err := strconv.Atoi(fields[i+1])
+		if err != nil {
+			return mcNode{}, err
+		}
+		if k < 0 || k >= len(nodes) {
+			return mcNode{}, fmt.Errorf("undefined node %d", k)
+		}
+		if k != 0 && nodes[k].level != n.level-1 {
+			return mcNode{}, fmt.Errorf("node %d has level %d, want %d", k, nodes[k].level, n.level-1)
+		}
+		n.kids[i] = k
+	}
+	return n, nil
+}
+
+// expandMacrocell paints live cells of node i into full at (r, c).
+func expandMacrocell(nodes []mcNode, i int, full [][]bool, r, c int) {
+	if i == 0 {
+		return
+	}
+	n := nodes[i]
+	if n.level == 3 {
+		for y, bits := range n.leaf {
+			for x := 0; x < 8; x++ {
+				if bits&(1<<uint(x)) != 0 {
+					full[r+y][c+x] = true
+				}
+			}
+		}
+		return
+	}
+	half := 1 << uint(n.level-1)
+	expandMacrocell(nodes, n.kids[0], full, r, c)
+	expandMacrocell(nodes, n.kids[1], full, r, c+half)
+	expandMacrocell(nodes, n.kids[2], full, r+half, c)
+	expandMacrocell(nodes, n.kids[3], full, r+half, c+half)
+}
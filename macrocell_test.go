@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewLifeFromMacrocell(t *testing.T) {
+	mc, err := NewLifeFromFile("testdata/gosperglidergun.mc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rle, err := NewLifeFromFile("testdata/gosperglidergun.rle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mc.cur.h != rle.cur.h || mc.cur.w != rle.cur.w {
+		t.Fatalf("got %dx%d field, want %dx%d", mc.cur.w, mc.cur.h, rle.cur.w, rle.cur.h)
+	}
+	for i := 0; i < rle.cur.h; i++ {
+		for j := 0; j < rle.cur.w; j++ {
+			if mc.cur.Alive(i, j) != rle.cur.Alive(i, j) {
+				t.Errorf("cell (%d, %d) is %v, want %v", i, j, mc.cur.Alive(i, j), rle.cur.Alive(i, j))
+			}
+		}
+	}
+	if mc.Rule() != Conway {
+		t.Errorf("rule %v, want %v", mc.Rule(), Conway)
+	}
+}
+
+func TestNewLifeFromMacrocellHeader(t *testing.T) {
+	src := "[M2] (golly 4.2)\n#R B36/S23\n#G 42\n$*$\n"
+	l, err := NewLifeFromMacrocell(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Rule().String(); got != "B36/S23" {
+		t.Errorf("rule %s, want B36/S23", got)
+	}
+	if l.gen != 42 {
+		t.Errorf("generation %d, want 42", l.gen)
+	}
+	if l.cur.h != 1 || l.cur.w != 1 || !l.cur.Alive(0, 0) {
+		t.Errorf("got %q, want a live cell", fieldRows(l.cur))
+	}
+}
+
+func TestNewLifeFromMacrocellError(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string // substring of the error
+	}{
+		{"#Life 1.06\n0 0\n", "header"},
+		{"[M2]\n", "no nodes"},
+		{"[M2]\n$$\n", "no live cells"},
+		{"[M2]\n*********$\n", "exceeds 8x8"},
+		{"[M2]\n$$$$$$$$*$\n", "exceeds 8x8"},
+		{"[M2]\n*x$\n", "unexpected character"},
+		{"[M2]\n*$\n4 1 0 0\n", "malformed node"},
+		{"[M2]\n*$\n2 1 0 0 0\n", "unsupported node level 2"},
+		{"[M2]\n*$\nfour 1 0 0 0\n", "line 3"},
+		{"[M2]\n*$\n4 1 0 0 2\n", "undefined node 2"},
+		{"[M2]\n*$\n4 -1 0 0 0\n", "undefined node -1"},
+		{"[M2]\n*$\n5 1 0 0 0\n", "node 1 has level 3, want 4"},
+		{"[M2]\n*$\n4 1 0 0 0\n5 2 0 0 0\n6 3 0 0 0\n7 4 0 0 0\n8 5 0 0 0\n9 6 0 0 0\n10 7 0 0 0\n11 8 0 0 0\n12 9 0 0 0\n13 10 0 0 0\n", "exceeds 2^12"},
+		{"[M2]\n#R B9/S\n*$\n", "line 2"},
+		{"[M2]\n#G many\n*$\n", "line 2"},
+	}
+	for _, tt := range tests {
+		_, err := NewLifeFromMacrocell(strings.NewReader(tt.src))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %v, want error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
@@ -5,23 +5,106 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"io/fs"
 	"log"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
+
+	"github.com/ymotongpoo/lifegame/terminal"
 )
 
 // Interval is display refresh interval.
 const Interval = time.Second / 10
 
+// exportGenerations is the number of generations written to GIF or video
+// unless specified.
+const exportGenerations = 300
+
+// soupDensity is the density of the random field used when no pattern is
+// specified.
+const soupDensity = 0.3
+
+// Topology is the way to treat outside of the field.
+type Topology int
+
+const (
+	// Torus wraps coordinates around the edges of the field.
+	Torus Topology = iota
+	// Fixed treats cells outside of the field as dead.
+	Fixed
+	// Mirror reflects coordinates at the edges of the field, so that -1 is
+	// 0 and h is h-1. Rows and columns are reflected independently at
+	// corners.
+	Mirror
+)
+
+var topologyNames = map[Topology]string{
+	Torus:  "torus",
+	Fixed:  "fixed",
+	Mirror: "mirror",
+}
+
+// topologyCoords maps coordinate x of the field of n cells on each
+// topology, and reports false when the cell is outside of the field and
+// dead.
+var topologyCoords = [...]func(x, n int) (int, bool){
+	Torus: func(x, n int) (int, bool) {
+		_, x = floorDiv(x, n)
+		return x, true
+	},
+	Fixed: func(x, n int) (int, bool) {
+		return x, x >= 0 && x < n
+	},
+	Mirror: func(x, n int) (int, bool) {
+		if _, x = floorDiv(x, 2*n); x >= n {
+			x = 2*n - 1 - x
+		}
+		return x, true
+	},
+}
+
+// String returns the name of the topology.
+func (t Topology) String() string {
+	if name, ok := topologyNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("Topology(%d)", int(t))
+}
+
+// parseTopology returns the topology of name.
+func parseTopology(name string) (Topology, error) {
+	for t, n := range topologyNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown topology %q", name)
+}
+
 // Field holds cell data.
 type Field struct {
-	cs   [][]bool // field's memory
-	w, h int      // field's width and height
+	cs    [][]bool    // field's memory
+	bits  [][]uint64  // field's memory packed by NewPackedField, used instead of cs
+	w, h  int         // field's width and height
+	rule  Rule        // rule to calculate next generation
+	topo  Topology    // how to treat outside of the field
+	ages  [][]int     // consecutive generations each cell has been alive, tracked only when not nil
+	heat  [][]float64 // activity of each cell, tracked only when not nil
+	dying [][]uint8   // generations since each cell died, tracked only for Generations rules
+
+	top, left int // position of the cell at (0, 0) on the plane, which moves as the field grows
 }
 
 // NewField returns a field which has w x h cells.
@@ -30,7 +113,7 @@ func NewField(h, w int) *Field {
 	for i := range cs {
 		cs[i] = make([]bool, w)
 	}
-	return &Field{cs: cs, w: w, h: h}
+	return &Field{cs: cs, w: w, h: h, rule: Conway}
 }
 
 // Set sets cell's status.
@@ -38,21 +121,243 @@ func (f *Field) Set(r, c int, b bool) error {
 	if r < 0 || r >= f.h || c < 0 || c >= f.w {
 		return errors.New("out of field")
 	}
-	f.cs[r][c] = b
+	f.setCell(r, c, b)
+	return nil
+}
+
+// Toggle flips cell's status.
+func (f *Field) Toggle(r, c int) error {
+	if r < 0 || r >= f.h || c < 0 || c >= f.w {
+		return errors.New("out of field")
+	}
+	f.setCell(r, c, !f.get(r, c))
 	return nil
 }
 
+// setCell sets cell's status, and its age when ages are tracked.
+func (f *Field) setCell(r, c int, b bool) {
+	f.put(r, c, b)
+	if f.ages != nil {
+		f.ages[r][c] = 0
+		if b {
+			f.ages[r][c] = 1
+		}
+	}
+	if f.dying != nil {
+		f.dying[r][c] = 0
+	}
+}
+
+// Clear sets all cells of the field dead in place.
+func (f *Field) Clear() {
+	for _, row := range f.cs {
+		for j := range row {
+			row[j] = false
+		}
+	}
+	for _, row := range f.bits {
+		for j := range row {
+			row[j] = 0
+		}
+	}
+	for _, row := range f.ages {
+		for j := range row {
+			row[j] = 0
+		}
+	}
+	for _, row := range f.heat {
+		for j := range row {
+			row[j] = 0
+		}
+	}
+	for _, row := range f.dying {
+		for j := range row {
+			row[j] = 0
+		}
+	}
+}
+
+// Resize changes the size of the field to newH x newW cells. Cells in the
+// overlapping top left region are kept, cells outside of the new size are
+// dropped, and new cells are dead. Negative sizes are treated as 0.
+func (f *Field) Resize(newH, newW int) {
+	f.resizeAt(newH, newW, 0, 0)
+}
+
+// resizeAt is like Resize but places the cell at (0, 0) at (offR, offC) of
+// the new field.
+func (f *Field) resizeAt(newH, newW, offR, offC int) {
+	if newH < 0 {
+		newH = 0
+	}
+	if newW < 0 {
+		newW = 0
+	}
+	var n *Field
+	if f.bits != nil {
+		n = NewPackedField(newH, newW)
+	} else {
+		n = NewField(newH, newW)
+	}
+	if f.ages != nil {
+		n.ages = newAges(newH, newW)
+	}
+	if f.heat != nil {
+		n.heat = newHeat(newH, newW)
+	}
+	if f.dying != nil {
+		n.dying = newDying(newH, newW)
+	}
+	for i := 0; i < f.h; i++ {
+		ni := i + offR
+		if ni < 0 || ni >= newH {
+			continue
+		}
+		for j := 0; j < f.w; j++ {
+			nj := j + offC
+			if nj < 0 || nj >= newW {
+				continue
+			}
+			n.put(ni, nj, f.get(i, j))
+			if n.ages != nil {
+				n.ages[ni][nj] = f.ages[i][j]
+			}
+			if n.heat != nil {
+				n.heat[ni][nj] = f.heat[i][j]
+			}
+			if n.dying != nil {
+				n.dying[ni][nj] = f.dying[i][j]
+			}
+		}
+	}
+	f.cs, f.bits, f.ages, f.heat, f.dying = n.cs, n.bits, n.ages, n.heat, n.dying
+	f.h, f.w = newH, newW
+}
+
+// Shift moves all cells by dr rows and dc columns. Cells moved beyond the
+// edges wrap around in Torus topology, and are dropped otherwise.
+func (f *Field) Shift(dr, dc int) {
+	if f.topo != Torus {
+		f.resizeAt(f.h, f.w, dr, dc)
+		return
+	}
+	if f.h == 0 || f.w == 0 {
+		return
+	}
+	_, dr = floorDiv(dr, f.h)
+	_, dc = floorDiv(dc, f.w)
+	old := f.Clone()
+	for i := 0; i < f.h; i++ {
+		ni := (i + dr) % f.h
+		for j := 0; j < f.w; j++ {
+			nj := (j + dc) % f.w
+			f.put(ni, nj, old.get(i, j))
+			if f.ages != nil {
+				f.ages[ni][nj] = old.ages[i][j]
+			}
+			if f.heat != nil {
+				f.heat[ni][nj] = old.heat[i][j]
+			}
+			if f.dying != nil {
+				f.dying[ni][nj] = old.dying[i][j]
+			}
+		}
+	}
+}
+
+// Center shifts the cells so that the bounding box of live cells is at
+// the center of the field. It does nothing on empty fields.
+func (f *Field) Center() {
+	minR, minC, maxR, maxC, ok := f.BoundingBox()
+	if !ok {
+		return
+	}
+	top := (f.h - (maxR - minR + 1)) / 2
+	left := (f.w - (maxC - minC + 1)) / 2
+	f.Shift(top-minR, left-minC)
+}
+
+// SetTopology sets how to treat outside of the field.
+func (f *Field) SetTopology(t Topology) {
+	f.topo = t
+}
+
+// SetRule sets the rule to calculate next generation by NextGen.
+func (f *Field) SetRule(r Rule) {
+	f.rule = r
+	switch {
+	case r.states <= 2:
+		f.dying = nil
+	case f.dying == nil:
+		f.dying = newDying(f.h, f.w)
+	}
+}
+
+// Width returns the number of columns of the field.
+func (f *Field) Width() int {
+	return f.w
+}
+
+// Height returns the number of rows of the field.
+func (f *Field) Height() int {
+	return f.h
+}
+
 // Alive confirm if specified cell is alive.
-// This is utility function to check outbound field.
+// This is utility function to check outbound field: coordinates are wrapped
+// around in Torus topology, cells outside of the field are dead in Fixed,
+// and coordinates are reflected in Mirror.
 func (f *Field) Alive(r, c int) bool {
-	r = (r + f.h) % f.h
-	c = (c + f.w) % f.w
-	return f.cs[r][c]
+	if uint(r) < uint(f.h) && uint(c) < uint(f.w) {
+		return f.get(r, c)
+	}
+	coord := topologyCoords[f.topo]
+	r, rok := coord(r, f.h)
+	c, cok := coord(c, f.w)
+	return rok && cok && f.get(r, c)
 }
 
 // NextGen returns if specified the cell of r & c will be alive
-// in next generation.
+// in next generation. For elementary cellular automata, it returns the cell
+// of column c in the row next to row r.
 func (f *Field) NextGen(r, c int) bool {
+	if f.rule.elementary {
+		return f.elementaryNext(r, c)
+	}
+	if f.rule.mapped {
+		return f.mapNext(r, c)
+	}
+	if f.rule.radius > 0 {
+		// Life counts cells of Larger than Life rules with prefix sums
+		// once per generation, while a single cell is counted directly.
+		return f.ltlNext(nil, r, c)
+	}
+	if f.dying != nil && f.dying[r][c] > 0 {
+		return false
+	}
+	return f.rule.next(f.Alive(r, c), f.LiveNeighbors(r, c))
+}
+
+// LiveNeighbors returns the number of live cells among the eight cells
+// around the cell of r & c, or the cells of the neighborhood of the rule,
+// following the topology of the field. The neighborhood of Larger than
+// Life rules is the square of the radius, with the cell itself counted
+// only when the rule includes the middle cell.
+func (f *Field) LiveNeighbors(r, c int) int {
+	if f.rule.radius > 0 {
+		return f.ltlCount(nil, r, c)
+	}
+	switch f.rule.nbhd {
+	case Hexagonal:
+		return f.hexNeighbors(r, c)
+	case VonNeumann:
+		return f.vonNeumannNeighbors(r, c)
+	}
+	if f.bits != nil {
+		if alive, ok := f.packedNeighbors(r, c); ok {
+			return alive
+		}
+	}
 	alive := 0
 	for i := -1; i <= 1; i++ {
 		for j := -1; j <= 1; j++ {
@@ -61,65 +366,367 @@ func (f *Field) NextGen(r, c int) bool {
 			}
 		}
 	}
-	return alive == 3 || alive == 2 && f.Alive(r, c)
+	return alive
+}
+
+// Population returns the number of live cells in the field.
+func (f *Field) Population() int {
+	if f.bits != nil {
+		return f.packedPopulation()
+	}
+	n := 0
+	for _, row := range f.cs {
+		for _, b := range row {
+			if b {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Empty reports whether the field has no live cells.
+func (f *Field) Empty() bool {
+	return f.Population() == 0
+}
+
+// BoundingBox returns the smallest rectangle from (minR, minC) to (maxR,
+// maxC) which contains all live cells. ok is false when the field is empty.
+func (f *Field) BoundingBox() (minR, minC, maxR, maxC int, ok bool) {
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			if !f.get(i, j) {
+				continue
+			}
+			if !ok {
+				minR, minC, maxR, maxC, ok = i, j, i, j, true
+				continue
+			}
+			if j < minC {
+				minC = j
+			}
+			if j > maxC {
+				maxC = j
+			}
+			maxR = i
+		}
+	}
+	return minR, minC, maxR, maxC, ok
+}
+
+// Trim returns a new field of the bounding box of live cells of f with the
+// rule and the topology of f, or a 0 x 0 field when f is empty. f is not
+// changed.
+func (f *Field) Trim() *Field {
+	minR, minC, maxR, maxC, ok := f.BoundingBox()
+	if !ok {
+		t := NewField(0, 0)
+		t.rule, t.topo = f.rule, f.topo
+		return t
+	}
+	h, w := maxR-minR+1, maxC-minC+1
+	if h == f.h && w == f.w {
+		return f.Clone()
+	}
+	return f.planeWindow(f.top+minR, f.left+minC, h, w)
+}
+
+// Equal reports whether f and other have the same size and the same cells.
+// Rules, topologies and storages of the fields are not compared.
+func (f *Field) Equal(other *Field) bool {
+	if f.h != other.h || f.w != other.w {
+		return false
+	}
+	if f.bits != nil && other.bits != nil {
+		for i, row := range f.bits {
+			for j, word := range row {
+				if word != other.bits[i][j] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			if f.get(i, j) != other.get(i, j) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Diff returns pairs of row and column of cells whose states differ in f
+// and other, in row-major order. Cells outside of the smaller field are
+// treated as dead.
+func (f *Field) Diff(other *Field) [][2]int {
+	h, w := f.h, f.w
+	if other.h > h {
+		h = other.h
+	}
+	if other.w > w {
+		w = other.w
+	}
+	var diff [][2]int
+	for i := 0; i < h; i++ {
+		for j := 0; j < w; j++ {
+			a := i < f.h && j < f.w && f.get(i, j)
+			b := i < other.h && j < other.w && other.get(i, j)
+			if a != b {
+				diff = append(diff, [2]int{i, j})
+			}
+		}
+	}
+	return diff
 }
 
 // Print display one generation status to stdout.
 func (f *Field) Print() {
-	for _, r := range f.cs {
-		bufr := make([]byte, f.w)
-		for j, c := range r {
-			if c {
-				bufr[j] = 'o'
+	f.Fprint(os.Stdout)
+}
+
+// Fprint display one generation status to w.
+func (f *Field) Fprint(w io.Writer) error {
+	return f.PrintWith(w, 'o', ' ')
+}
+
+// PrintWith display one generation status to w, using alive for live cells
+// and dead for dead cells. The whole field is written at once.
+func (f *Field) PrintWith(w io.Writer, alive, dead rune) error {
+	var buf bytes.Buffer
+	f.renderText(&buf, alive, dead)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// renderText appends rows of the field to buf, using alive for live cells
+// and dead for dead cells.
+func (f *Field) renderText(buf *bytes.Buffer, alive, dead rune) {
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			if f.get(i, j) {
+				buf.WriteRune(alive)
 			} else {
-				bufr[j] = ' '
+				buf.WriteRune(dead)
 			}
 		}
-		fmt.Println(string(bufr))
+		buf.WriteByte('\n')
 	}
 }
 
+// WriteTo writes the field in the same text format as NewLifeFromFile reads.
+// Each row is written as f.w cells followed by a newline.
+func (f *Field) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	f.renderText(&buf, 'o', ' ')
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
 // Life holds current and next generation field.
 type Life struct {
 	cur, next *Field
 	gen       int
+	mode      RenderMode
+	border    bool // whether to draw a border around the field, set by SetBorder
+
+	// parameters of activity set by TrackActivity
+	heatDecay, heatGain, heatMin float64
+
+	sums    [][]int // prefix sums of cur for Larger than Life rules
+	workers int     // number of goroutines of Next, or 0 to choose by the size of the field
+
+	history []*Field // previous generations for Prev, the latest last
+	depth   int      // maximum length of history
+
+	// parameters of growth set by SetGrowth
+	growMargin, growMax int
+	growErr             error
+
+	// spans of activeCols cells of each row which next generation is
+	// calculated of, around the cells of activeFor which changed from the
+	// previous generation, and spans within one cell of the cells changing
+	// in next generation.
+	active, changed [][]bool
+	activeFor       *Field
 }
 
-// NewLife create new lifegame buffer.
+// packedThreshold is the number of cells above which New packs cells into
+// words by NewPackedField. Larger [][]bool fields do not fit in cache.
+const packedThreshold = 256 * 256
+
+// NewLife create new lifegame buffer with Conway's rule.
+// It is New with WithInit, after checking that init has h rows of w cells.
 func NewLife(h, w int, init [][]bool) (*Life, error) {
-	cur := NewField(h, w)
-	next := NewField(h, w)
-	if len(init) != h || len(init[0]) != w {
-		return nil, errors.New("Wrong init size")
+	if len(init) == 0 {
+		return nil, errors.New("Wrong init size: init is empty")
+	}
+	if len(init) != h {
+		return nil, fmt.Errorf("Wrong init size: %d rows, want %d", len(init), h)
 	}
-	cur.cs = init
-	return &Life{cur: cur, next: next, gen: 0}, nil
+	for i, row := range init {
+		if len(row) != w {
+			return nil, fmt.Errorf("Wrong init size: row %d has %d cells, want %d", i, len(row), w)
+		}
+	}
+	return New(h, w, WithInit(init)), nil
+}
+
+// NewLifeRule is like NewLife but calculates generations by rule r instead
+// of Conway's.
+func NewLifeRule(h, w int, init [][]bool, r Rule) (*Life, error) {
+	l, err := NewLife(h, w, init)
+	if err != nil {
+		return nil, err
+	}
+	l.SetRule(r)
+	return l, nil
+}
+
+// Clone returns a copy of l which shares no buffers with l. The generation
+// counter is kept.
+func (l *Life) Clone() *Life {
+	c := *l
+	c.cur = l.cur.Clone()
+	c.next = l.next.Clone()
+	c.history = make([]*Field, len(l.history))
+	for i, f := range l.history {
+		c.history[i] = f.Clone()
+	}
+	c.active, c.changed, c.activeFor = nil, nil, nil
+	return &c
+}
+
+// Rule returns the rule of the lifegame.
+func (l *Life) Rule() Rule {
+	return l.cur.rule
+}
+
+// Population returns the number of live cells in current generation.
+func (l *Life) Population() int {
+	return l.cur.Population()
+}
+
+// SetRule sets the rule to calculate next generation.
+func (l *Life) SetRule(r Rule) {
+	l.cur.rule = r
+	l.next.rule = r
+	l.trackDying()
+	l.clearHistory()
+}
+
+// SetRenderMode sets the way to display generations.
+func (l *Life) SetRenderMode(m RenderMode) {
+	l.mode = m
+	if m == Color && l.cur.ages == nil {
+		l.trackAges()
+	}
+	if m == Heatmap && l.cur.heat == nil {
+		l.TrackActivity(DefaultHeatDecay, DefaultHeatWindow)
+	}
+}
+
+// SetBorder sets whether Print, Fprint and RunContext draw a border of box
+// drawing characters around the field.
+func (l *Life) SetBorder(b bool) {
+	l.border = b
+}
+
+// Reset clears current generation and sets the generation counter back to
+// 0. The rule and the topology are kept.
+func (l *Life) Reset() {
+	l.cur.Clear()
+	l.gen = 0
+	l.clearHistory()
+}
+
+// Resize changes the size of the field as Field.Resize does, keeping the
+// generation counter and the rule.
+func (l *Life) Resize(newH, newW int) {
+	l.cur.Resize(newH, newW)
+	l.next = l.cur.blank()
+	l.clearHistory()
+}
+
+// SetTopology sets how to treat outside of the field.
+func (l *Life) SetTopology(t Topology) {
+	l.cur.topo = t
+	l.next.topo = t
+	l.clearHistory()
 }
 
 // NewLifeFromFile create new lifegame buffer from text file.
+// Files with .rle extension are read as Run Length Encoded pattern,
+// files with .cells extension are read as plaintext pattern, files
+// with .lif or .life extension are read as Life 1.05 or 1.06 pattern, and
+// files with .mc extension are read as macrocell pattern.
+// Gzip compressed files are decompressed transparently.
 func NewLifeFromFile(path string) (*Life, error) {
-	var err error
-	buf, err := ioutil.ReadFile(path)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return newLifeFromNamed(file, path)
+}
+
+// NewLifeFromFS is like NewLifeFromFile but reads the file of name from
+// fsys, such as patterns embedded by go:embed.
+func NewLifeFromFS(fsys fs.FS, name string) (*Life, error) {
+	file, err := fsys.Open(name)
 	if err != nil {
 		return nil, err
 	}
-	reader := bufio.NewReader(bytes.NewReader(buf))
+	defer file.Close()
+	return newLifeFromNamed(file, name)
+}
+
+// newLifeFromNamed create new lifegame buffer from pattern read from r in
+// the format of the extension of name, as NewLifeFromFile does.
+func newLifeFromNamed(r io.Reader, name string) (*Life, error) {
+	parse := formatFromExt(name)
+	if parse == nil {
+		parse = newLifeFromText
+	}
+	l, err := readPattern(bufio.NewReader(r), parse)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return l, nil
+}
+
+// NewLifeFromReader create new lifegame buffer from pattern read from r.
+// The format of the pattern is detected from its content, and falls back to
+// the text format which NewLifeFromFile reads. Gzip compressed pattern is
+// decompressed transparently.
+func NewLifeFromReader(r io.Reader) (*Life, error) {
+	return readPattern(bufio.NewReader(r), nil)
+}
+
+// newLifeFromText create new lifegame buffer from text read from r.
+func newLifeFromText(r io.Reader) (*Life, error) {
+	var err error
+	reader := bufio.NewReader(r)
 
 	// first line
 	line, err := reader.ReadBytes('\n')
-	if err != nil {
+	if err != nil && (err != io.EOF || len(line) == 0) {
 		return nil, err
 	}
+	line = trimEOL(line)
 	colsize := len(line)
 	firstRow := bytesToBool(line)
 
 	init := [][]bool{}
 	init = append(init, firstRow)
-	for {
-		line, err := reader.ReadBytes('\n')
-		if err != nil {
+	for err == nil {
+		// the last line may not be terminated by newline.
+		line, err = reader.ReadBytes('\n')
+		if len(line) == 0 {
 			break
 		}
+		line = trimEOL(line)
 		if len(line) != colsize {
 			return nil, errors.New("column size is not appropriate")
 		}
@@ -135,6 +742,25 @@ func NewLifeFromFile(path string) (*Life, error) {
 	return l, nil
 }
 
+// Save writes current generation to the file of path in text format.
+func (l *Life) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := l.cur.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// trimEOL removes trailing "\n" or "\r\n" from line.
+func trimEOL(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	return bytes.TrimSuffix(line, []byte{'\r'})
+}
+
 func bytesToBool(line []byte) []bool {
 	b := make([]bool, len(line))
 	for i, c := range line {
@@ -147,39 +773,666 @@ func bytesToBool(line []byte) []bool {
 	return b
 }
 
+// parallelThreshold is the number of cells from which Next calculates
+// the next generation in parallel.
+const parallelThreshold = 256 * 256
+
 // Next calculates each state of all cells in current field and set it in next.
 // Swaps cur and next after calculation and proceed generation counter.
+// Large fields are calculated in parallel by NextParallel, unless the
+// number of workers is set by SetWorkers.
 func (l *Life) Next() {
-	for i, r := range l.cur.cs {
-		for j := range r {
-			l.next.Set(i, j, l.cur.NextGen(i, j))
+	l.Step()
+}
+
+// SetWorkers sets the number of goroutines calculating each generation.
+// When n is 0, which is the default, large fields are calculated by
+// GOMAXPROCS goroutines and small fields sequentially. Fields are always
+// calculated sequentially when n is 1. Negative n is treated as 0.
+func (l *Life) SetWorkers(n int) {
+	if n < 0 {
+		n = 0
+	}
+	l.workers = n
+}
+
+// Step is like Next but returns the number of cells which were born and
+// died in the transition.
+func (l *Life) Step() (births, deaths int) {
+	n := l.workers
+	if n == 0 && l.cur.h*l.cur.w >= parallelThreshold {
+		n = runtime.GOMAXPROCS(0)
+	}
+	if n > 1 {
+		return l.stepParallel(n)
+	}
+	l.prepare()
+	births, deaths = l.nextRows(0, l.cur.h)
+	l.swap()
+	return births, deaths
+}
+
+// NextParallel is like Next, but splits rows into n contiguous bands and
+// calculates each band in its own goroutine.
+func (l *Life) NextParallel(n int) {
+	l.stepParallel(n)
+}
+
+// stepParallel is NextParallel returning births and deaths as Step does.
+func (l *Life) stepParallel(n int) (births, deaths int) {
+	if n > l.cur.h {
+		n = l.cur.h
+	}
+	if n < 1 {
+		n = 1
+	}
+	l.prepare()
+	counts := make([][2]int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i, lo, hi int) {
+			defer wg.Done()
+			counts[i][0], counts[i][1] = l.nextRows(lo, hi)
+		}(i, l.cur.h*i/n, l.cur.h*(i+1)/n)
+	}
+	wg.Wait()
+	l.swap()
+	for _, c := range counts {
+		births += c[0]
+		deaths += c[1]
+	}
+	return births, deaths
+}
+
+// prepare grows the field if needed, and calculates what nextRows shares
+// among rows, which is the prefix sums of Larger than Life rules, and the
+// active region.
+func (l *Life) prepare() {
+	l.grow()
+	l.sums = nil
+	if l.cur.rule.radius > 0 {
+		l.sums = l.cur.prefixSums()
+	}
+	l.prepareActive()
+}
+
+// nextRows calculates rows from lo to hi-1 of next generation, and returns
+// the number of cells born and died in the rows.
+// Only the rows are written, so that bands of rows can be calculated concurrently.
+func (l *Life) nextRows(lo, hi int) (births, deaths int) {
+	if l.cur.rule.elementary {
+		l.nextElementaryRows(lo, hi)
+		return l.changes(lo, hi)
+	}
+	for i := lo; i < hi; i++ {
+		if l.active == nil {
+			b, d := l.nextCells(i, 0, l.cur.w)
+			births, deaths = births+b, deaths+d
+			continue
+		}
+		// cells out of the active region are the same as current ones.
+		l.copyRow(i)
+		for s, on := range l.active[i] {
+			if on {
+				b, d := l.nextCells(i, s*activeCols, s*activeCols+activeCols)
+				births, deaths = births+b, deaths+d
+			}
+		}
+	}
+	return births, deaths
+}
+
+// nextCells calculates cells from lo to hi-1 of row i of next generation,
+// and returns the number of cells born and died in them.
+func (l *Life) nextCells(i, lo, hi int) (births, deaths int) {
+	if hi > l.cur.w {
+		hi = l.cur.w
+	}
+	for j := lo; j < hi; j++ {
+		var b bool
+		if l.sums != nil {
+			b = l.cur.ltlNext(l.sums, i, j)
+		} else {
+			b = l.cur.NextGen(i, j)
+		}
+		l.next.put(i, j, b)
+		alive := l.cur.get(i, j)
+		if b && !alive {
+			births++
+		} else if !b && alive {
+			deaths++
+		}
+		changed := b != alive
+		if l.next.ages != nil {
+			if b {
+				l.next.ages[i][j] = l.cur.ages[i][j] + 1
+			} else {
+				l.next.ages[i][j] = 0
+			}
+		}
+		if l.next.heat != nil {
+			l.next.heat[i][j] = l.activity(l.cur.heat[i][j], b)
+		}
+		if l.next.dying != nil {
+			l.next.dying[i][j] = l.cur.nextDying(i, j, b)
+			changed = changed || l.next.dying[i][j] != l.cur.dying[i][j]
+		}
+		if changed && l.changed != nil {
+			l.markChanged(i, j)
 		}
 	}
+	return births, deaths
+}
+
+// changes returns the number of cells born and died in rows from lo to hi-1
+// from cur to next.
+func (l *Life) changes(lo, hi int) (births, deaths int) {
+	for i := lo; i < hi; i++ {
+		for j := 0; j < l.cur.w; j++ {
+			if alive, b := l.cur.get(i, j), l.next.get(i, j); b && !alive {
+				births++
+			} else if !b && alive {
+				deaths++
+			}
+		}
+	}
+	return births, deaths
+}
+
+// swap swaps cur and next and proceed generation counter. The field which
+// is no longer kept by the history is reused as next generation, since
+// nextRows overwrites all cells of it.
+func (l *Life) swap() {
+	prev := l.cur
 	l.cur = l.next
-	l.next = NewField(l.cur.w, l.cur.h)
+	l.next = l.cur.reuse(l.push(prev))
 	l.gen++
+	l.spreadActive()
 }
 
-// Print display current generation status.
+// Print display current generation status to stdout, redrawing the screen.
 func (l *Life) Print() {
-	cmd := exec.Command("clear") // TODO(ymotongpoo): Work out way to clear terminal on Windows.
-	cmd.Stdout = os.Stdout
+	l.printTo(os.Stdout)
+}
+
+// Fprint display current generation status to w. Unlike Print, it does not
+// redraw the screen.
+func (l *Life) Fprint(w io.Writer) error {
+	r := NewTextRenderer(w, l.mode)
+	r.Border = l.border
+	return r.Render(l.cur, l.gen)
+}
+
+// printTo redraws the screen with current generation status to w.
+func (l *Life) printTo(w io.Writer) {
+	l.terminalRenderer(w).Render(l.cur, l.gen)
+}
+
+// terminalRenderer returns TerminalRenderer to w in the render mode and the
+// border of l.
+func (l *Life) terminalRenderer(w io.Writer) *TerminalRenderer {
+	r := NewTerminalRenderer(w, l.mode)
+	r.Border = l.border
+	return r
+}
+
+// clearScreen clears the terminal which w is connected to by clear command,
+// or cls on Windows. It is used only when the terminal does not accept ANSI
+// escape sequences, such as console of Windows before 10.
+func clearScreen(w io.Writer) {
+	cmd := exec.Command("clear")
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", "cls")
+	}
+	cmd.Stdout = w
 	cmd.Run()
-	fmt.Printf("---------- %vth generation\n", l.gen)
-	l.cur.Print()
+}
+
+// RunContext displays current generation to out and advances a generation
+// at every interval, until ctx is cancelled. It returns ctx.Err().
+func (l *Life) RunContext(ctx context.Context, interval time.Duration, out io.Writer) error {
+	return l.runContext(ctx, interval, l.terminalRenderer(out), nil)
+}
+
+// runContext is like RunContext but renders each generation with r, and
+// calls fn after that. It returns nil when fn returns false, or the error
+// of r.
+func (l *Life) runContext(ctx context.Context, interval time.Duration, r Renderer, fn func(*Life) bool) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if err := r.Render(l.cur, l.gen); err != nil {
+			return err
+		}
+		if fn != nil && !fn(l) {
+			return nil
+		}
+		l.Next()
+	}
+}
+
+// Run advances generations generations and returns. It does nothing when
+// generations is zero or negative. Use RunFunc with Print to display each
+// generation.
+// Run stops early when all cells die, and returns the generation at which
+// the field became empty, or -1 when it did not.
+func (l *Life) Run(generations int) int {
+	return l.RunFunc(generations, nil)
+}
+
+// RunFunc is like Run but calls fn after each step, if fn is not nil.
+func (l *Life) RunFunc(generations int, fn func(*Life)) int {
+	for i := 0; i < generations; i++ {
+		if l.extinct() {
+			return l.gen
+		}
+		l.Next()
+		if fn != nil {
+			fn(l)
+		}
+	}
+	if l.extinct() {
+		return l.gen
+	}
+	return -1
+}
+
+// extinct reports whether all cells died and no cells are born any more.
+func (l *Life) extinct() bool {
+	return !l.cur.rule.birthFromEmpty() && l.cur.Empty()
+}
+
+// isTerminal reports whether f is connected to a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
 }
 
 func main() {
-	fmt.Println("Lifegame")
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		if err := analyzeMain(os.Args[2:], os.Stdout); err != nil && err != flag.ErrHelp {
+			log.Fatal(err)
+		}
+		return
+	}
+	file := flag.String("file", "", "start from the pattern of `file` or URL, or - for stdin")
+	interval := flag.Duration("interval", Interval, "`interval` between generations")
+	pattern := flag.String("pattern", "", "start from the built-in `name`d pattern")
+	apgcode := flag.String("apgcode", "", "start from the object of `apgcode` such as xq4_153")
+	elementary := flag.Bool("1d", false, "run elementary cellular automaton of -rule number such as 110, default 30")
+	random := flag.Float64("random", 0, "start from random field where cells are alive with probability `density`")
+	ruleFlag := flag.String("rule", "", "`rule` such as B36/S23, R5,B34..45,S34..58 or highlife, overriding the rule of the pattern")
+	topology := flag.String("topology", "", "`topology` of the field: torus, fixed where cells outside are dead, or mirror, overriding that of the pattern")
+	grow := flag.Int("grow", 0, "grow the field when live cells are within `margin` cells of its edges, or 0 not to grow")
+	maxCells := flag.Int("max-cells", DefaultMaxCells, "maximum number of `cells` of the field growing by -grow")
+	workers := flag.Int("workers", 0, "number of `goroutines` calculating each generation, or 0 to choose automatically")
+	neighborhood := flag.String("neighborhood", "", "`neighborhood` of the rule: moore, vonneumann or hexagonal")
+	listRules := flag.Bool("list-rules", false, "list rule presets and exit")
+	seed := flag.Int64("seed", 1, "`seed` of random field")
+	size := flag.String("size", "40x80", "`size` of random field as HEIGHTxWIDTH")
+	listPatterns := flag.Bool("list-patterns", false, "list built-in patterns and exit")
+	checkpoint := flag.String("checkpoint", "", "write the state to `file` periodically")
+	checkpointEvery := flag.Int("checkpoint-every", 100, "write checkpoint every `n` generations")
+	restore := flag.String("restore", "", "resume from checkpoint `file`")
+	snapshot := flag.String("snapshot", "", "write the first generation to PNG or SVG `file` and exit")
+	cellSize := flag.Int("cellsize", 8, "size of each cell in `pixels` of images")
+	grid := flag.Bool("grid", false, "draw grid lines between cells of images")
+	render := flag.String("render", "ascii", "render `mode`: ascii, halfblock, braille, color or heatmap")
+	heatDecay := flag.Float64("heat-decay", DefaultHeatDecay, "`factor` of decay of activity per generation in heatmap")
+	heatWindow := flag.Int("heat-window", DefaultHeatWindow, "number of `generations` of activity displayed in heatmap")
+	aliveGlyph := flag.String("alive", string(DefaultGlyphs.Alive), "`character` to display live cells")
+	deadGlyph := flag.String("dead", string(DefaultGlyphs.Dead), "`character` to display dead cells")
+	headerFlag := flag.String("header", "", "`template` of header line such as \"gen {{.Gen}} pop {{.Population}}\"")
+	noHeader := flag.Bool("no-header", false, "do not display header line")
+	border := flag.Bool("border", false, "draw a border around the field")
+	colorFlag := flag.String("color", "auto", "color cells by age: auto, always or never")
+	fullscreen := flag.Bool("fullscreen", true, "use full-screen UI when stdout is a terminal")
+	stopStable := flag.Bool("stop-stable", false, "stop when the pattern becomes a still life or an oscillator")
+	gifPath := flag.String("gif", "", "write animated GIF of the run to `file` and exit")
+	generations := flag.Int("generations", 0, "number of `generations` to run, or 0 for infinite; GIF and video default to 300")
+	gifDelay := flag.Int("gif-delay", int(Interval/(10*time.Millisecond)), "`delay` between GIF frames in 100ths of a second")
+	gifEvery := flag.Int("gif-every", 1, "write GIF frame every `n` generations")
+	video := flag.String("video", "", "write video of the run to `file` with ffmpeg and exit")
+	fps := flag.Int("fps", int(time.Second/Interval), "frame `rate` of video")
+	flag.Parse()
 
-	l, err := NewLifeFromFile("init.txt")
+	if *listPatterns {
+		ps := Patterns()
+		for _, name := range PatternNames() {
+			fmt.Printf("%-12s %dx%d\n", name, ps[name].w, ps[name].h)
+		}
+		return
+	}
+	if *listRules {
+		for _, name := range RuleNames() {
+			fmt.Printf("%-16s %s\n", name, rulePresets[name])
+		}
+		return
+	}
+
+	mode, err := parseRenderMode(*render)
 	if err != nil {
-		log.Fatalf("NewLifeFromFile: %v", err)
+		log.Fatal(err)
+	}
+	var topo Topology
+	if *topology != "" {
+		if topo, err = parseTopology(*topology); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var rule Rule
+	if *ruleFlag != "" {
+		s := *ruleFlag
+		if *elementary && isCounts(s) {
+			s = "W" + s
+		}
+		if rule, err = LookupRule(s); err != nil {
+			log.Fatal(err)
+		}
+		if *elementary && !rule.elementary {
+			log.Fatalf("rule %v is not of elementary cellular automata", rule)
+		}
+	}
+	var glyphs Glyphs
+	if glyphs.Alive, err = parseGlyph(*aliveGlyph); err != nil {
+		log.Fatal(err)
+	}
+	if glyphs.Dead, err = parseGlyph(*deadGlyph); err != nil {
+		log.Fatal(err)
+	}
+	if err := glyphs.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	header := DefaultHeader
+	switch {
+	case *noHeader:
+		header = nil
+	case *headerFlag != "":
+		if header, err = ParseHeader(*headerFlag); err != nil {
+			log.Fatalf("ParseHeader: %v", err)
+		}
+	}
+	switch *colorFlag {
+	case "always":
+		mode = Color
+	case "auto":
+		if mode == ASCII && isTerminal(os.Stdout) && os.Getenv("TERM") != "dumb" {
+			mode = Color
+		}
+	case "never":
+		if mode == Color {
+			mode = ASCII
+		}
+	default:
+		log.Fatalf("unknown color mode %q", *colorFlag)
 	}
 
-	ticker := time.Tick(Interval)
-	for range ticker {
-		l.Print()
-		l.Next()
+	fmt.Println("Lifegame")
+
+	var l *Life
+	arg := flag.Arg(0)
+	if *file != "" {
+		arg = *file
+	}
+	// random soup is used when no pattern is given at all.
+	soup := arg == "" && *pattern == "" && isTerminal(os.Stdin)
+	switch {
+	case *restore != "":
+		l, err = loadCheckpoint(*restore)
+		if err != nil {
+			log.Fatalf("loadCheckpoint: %v", err)
+		}
+	case *apgcode != "":
+		f, err := ParseApgcode(*apgcode)
+		if err != nil {
+			log.Fatalf("ParseApgcode: %v", err)
+		}
+		l, err = newLifeWithMargin(f, patternMargin)
+		if err != nil {
+			log.Fatalf("newLifeWithMargin: %v", err)
+		}
+	case *elementary:
+		h, w, err := parseSize(*size)
+		if err != nil {
+			log.Fatal(err)
+		}
+		n := 30
+		if rule.elementary {
+			n = int(rule.wolfram)
+		}
+		l, err = NewElementaryLife(h, w, n)
+		if err != nil {
+			log.Fatalf("NewElementaryLife: %v", err)
+		}
+	case *random != 0, soup:
+		h, w, err := parseSize(*size)
+		if err != nil {
+			log.Fatal(err)
+		}
+		density := *random
+		if density == 0 {
+			density = soupDensity
+		}
+		l, err = NewRandomLife(h, w, density, *seed)
+		if err != nil {
+			log.Fatalf("NewRandomLife: %v", err)
+		}
+	case *pattern != "":
+		l, err = NewLifeFromPattern(*pattern, patternMargin)
+		if err != nil {
+			log.Fatalf("NewLifeFromPattern: %v", err)
+		}
+	case arg == "-", arg == "" && !isTerminal(os.Stdin):
+		l, err = NewLifeFromReader(os.Stdin)
+		if err != nil {
+			log.Fatalf("NewLifeFromReader: %v", err)
+		}
+	case strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://"):
+		l, err = NewLifeFromURL(arg, FetchTimeout)
+		if err != nil {
+			log.Fatalf("NewLifeFromURL: %v", err)
+		}
+	default:
+		l, err = NewLifeFromFile(arg)
+		if err != nil {
+			log.Fatalf("NewLifeFromFile: %v", err)
+		}
+	}
+
+	if *ruleFlag != "" {
+		l.SetRule(rule)
+	}
+	if *topology != "" {
+		l.SetTopology(topo)
+	}
+	l.SetWorkers(*workers)
+	if *grow != 0 {
+		if err := l.SetGrowth(*grow, *maxCells); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *neighborhood != "" {
+		n, err := parseNeighborhood(*neighborhood)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r, err := l.Rule().WithNeighborhood(n)
+		if err != nil {
+			log.Fatal(err)
+		}
+		l.SetRule(r)
+	}
+	if mode == Heatmap {
+		if err := l.TrackActivity(*heatDecay, *heatWindow); err != nil {
+			log.Fatalf("TrackActivity: %v", err)
+		}
+	}
+	l.SetRenderMode(mode)
+
+	if *snapshot != "" {
+		if strings.EqualFold(filepath.Ext(*snapshot), ".svg") {
+			err = saveSVG(*snapshot, l.cur, *cellSize)
+		} else {
+			err = savePNG(*snapshot, l.cur, *cellSize, *grid)
+		}
+		if err != nil {
+			log.Fatalf("snapshot: %v", err)
+		}
+		return
+	}
+	n := *generations
+	if n <= 0 {
+		n = exportGenerations
+	}
+	if *video != "" {
+		if err := saveVideo(*video, l, n, *cellSize, *fps); err != nil {
+			log.Fatalf("saveVideo: %v", err)
+		}
+		return
+	}
+	if *gifPath != "" {
+		if err := saveGIF(*gifPath, l, n, *gifEvery, *cellSize, *gifDelay); err != nil {
+			log.Fatalf("saveGIF: %v", err)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var term *terminal.Terminal
+	keys := make(chan byte, 16)
+	if *fullscreen && isTerminal(os.Stdout) {
+		term, err = openTerminal()
+		switch {
+		case err == terminal.ErrNotSupported:
+			term = nil
+		case err != nil:
+			log.Printf("openTerminal: %v", err)
+			term = nil
+		default:
+			defer term.Restore()
+			go readKeys(term.Keys(), keys, cancel)
+		}
+	}
+
+	var cycle cycleDetector
+	var stable string
+	renderer := NewDiffRenderer(os.Stdout, l.mode)
+	renderer.Glyphs = glyphs
+	renderer.Header = header
+	renderer.Border = *border
+	view := newViewRenderer(renderer, l.mode)
+	if isTerminal(os.Stdout) {
+		if rows, cols, err := terminal.Size(os.Stdout); err == nil {
+			view.SetSize(rows, cols)
+		}
+		resize := make(chan os.Signal, 1)
+		notifyResize(resize)
+		go func() {
+			for range resize {
+				if rows, cols, err := terminal.Size(os.Stdout); err == nil {
+					view.SetSize(rows, cols)
+				}
+				renderer.Reset()
+			}
+		}()
+	}
+	err = l.runContext(ctx, *interval, view, func(l *Life) bool {
+		if *stopStable {
+			if p := cycle.observe(l); p > 0 {
+				stable = fmt.Sprintf("stabilized at %vth generation with period %v\n", l.gen, p)
+				return false
+			}
+		}
+		if *generations > 0 && l.gen >= *generations {
+			return false
+		}
+		if l.extinct() {
+			stable = fmt.Sprintf("died out at %vth generation\n", l.gen)
+			return false
+		}
+		if err := l.GrowthErr(); err != nil {
+			stable = fmt.Sprintf("stopped at %vth generation: %v\n", l.gen, err)
+			return false
+		}
+		if *checkpoint != "" && *checkpointEvery > 0 && l.gen > 0 && l.gen%*checkpointEvery == 0 {
+			if err := saveCheckpoint(*checkpoint, l); err != nil {
+				log.Printf("saveCheckpoint: %v", err)
+			}
+		}
+		for n := len(keys); n > 0; n-- {
+			k := <-keys
+			view.Update(l.cur, func(v *Viewport) { v.HandleKey(l.cur, k) })
+		}
+		if term != nil {
+			term.Status(fmt.Sprintf(" generation %v | population %v | hjkl: pan +-: zoom c: center q: quit ", l.gen, l.Population()))
+		}
+		return true
+	})
+	if term != nil {
+		term.Restore()
+	}
+	fmt.Print(stable)
+	if err != nil && err != context.Canceled {
+		log.Fatalf("runContext: %v", err)
+	}
+}
+
+// arrowKeys maps the final bytes of escape sequences of arrow keys to the
+// keys of the same direction.
+var arrowKeys = map[byte]byte{'A': 'k', 'B': 'j', 'C': 'l', 'D': 'h'}
+
+// readKeys sends bytes read from in to out, translating arrow keys to hjkl.
+// It calls quit on q. Keys are dropped while out is full.
+func readKeys(in <-chan byte, out chan<- byte, quit func()) {
+	esc := 0 // number of bytes of escape sequence read
+	for k := range in {
+		switch {
+		case esc == 0 && k == 0x1b:
+			esc = 1
+			continue
+		case esc == 1 && k == '[':
+			esc = 2
+			continue
+		case esc == 2:
+			esc = 0
+			k = arrowKeys[k]
+		default:
+			esc = 0
+		}
+		if k == 'q' {
+			quit()
+			continue
+		}
+		select {
+		case out <- k:
+		default:
+		}
+	}
+}
+
+// openTerminal switches stdout into full-screen mode. Keys are read from
+// stdin, or from /dev/tty when stdin is used to read the pattern.
+func openTerminal() (*terminal.Terminal, error) {
+	in := os.Stdin
+	if !isTerminal(in) {
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			return nil, err
+		}
+		in = tty
 	}
+	return terminal.Open(in, os.Stdout)
 }
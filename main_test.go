@@ -0,0 +1,670 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fieldRows returns rows of f as strings of 'o' for live cells and '.' for
+// dead cells, in the form of patternField.
+func fieldRows(f *Field) []string {
+	rows := make([]string, f.h)
+	for i := range rows {
+		var b strings.Builder
+		for j := 0; j < f.w; j++ {
+			if f.get(i, j) {
+				b.WriteByte('o')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		rows[i] = b.String()
+	}
+	return rows
+}
+
+// neighborCounts returns rows of the numbers of live neighbors of each cell
+// of f as digits.
+func neighborCounts(f *Field) []string {
+	rows := make([]string, f.h)
+	for i := range rows {
+		var b strings.Builder
+		for j := 0; j < f.w; j++ {
+			fmt.Fprint(&b, f.LiveNeighbors(i, j))
+		}
+		rows[i] = b.String()
+	}
+	return rows
+}
+
+func TestNextNonSquare(t *testing.T) {
+	tests := []struct {
+		name string
+		init []string
+		gens int
+		topo Topology
+		want []string
+	}{
+		{
+			name: "blinker 5x9",
+			init: []string{
+				".........",
+				".........",
+				"...ooo...",
+				".........",
+				".........",
+			},
+			gens: 3,
+			want: []string{
+				".........",
+				"....o....",
+				"....o....",
+				"....o....",
+				".........",
+			},
+		},
+		{
+			name: "blinker 9x5",
+			init: []string{
+				".....",
+				".....",
+				".....",
+				"..o..",
+				"..o..",
+				"..o..",
+				".....",
+				".....",
+				".....",
+			},
+			gens: 5,
+			want: []string{
+				".....",
+				".....",
+				".....",
+				".....",
+				".ooo.",
+				".....",
+				".....",
+				".....",
+				".....",
+			},
+		},
+		{
+			// the glider moves by one cell down and right in 4 generations,
+			// wrapping around the edges of torus.
+			name: "glider 5x9 torus",
+			init: []string{
+				"......o..",
+				".......o.",
+				".....ooo.",
+				".........",
+				".........",
+			},
+			gens: 12,
+			want: []string{
+				"oo......o",
+				".........",
+				".........",
+				"o........",
+				".o.......",
+			},
+		},
+		{
+			name: "glider 5x9 fixed",
+			init: []string{
+				".o.......",
+				"..o......",
+				"ooo......",
+				".........",
+				".........",
+			},
+			gens: 4,
+			topo: Fixed,
+			want: []string{
+				".........",
+				"..o......",
+				"...o.....",
+				".ooo.....",
+				".........",
+			},
+		},
+	}
+	for _, tt := range tests {
+		f := patternField(tt.init)
+		l, err := NewLife(f.h, f.w, f.cs)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		l.SetTopology(tt.topo)
+		l.Run(tt.gens)
+		if got := fieldRows(l.cur); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestLiveNeighborsEdges(t *testing.T) {
+	corner := []string{"o....", ".....", ".....", "....."}
+	corners := []string{"o...o", ".....", ".....", "o...o"}
+	full := []string{"ooooo", "ooooo", "ooooo", "ooooo"}
+	tests := []struct {
+		rows []string
+		topo Topology
+		want []string // neighbor counts of each cell
+	}{
+		{corner, Torus, []string{"01001", "11001", "00000", "11001"}},
+		{corner, Fixed, []string{"01000", "11000", "00000", "00000"}},
+		{corner, Mirror, []string{"32000", "21000", "00000", "00000"}},
+		{corners, Torus, []string{"32023", "21012", "21012", "32023"}},
+		{corners, Fixed, []string{"01010", "11011", "11011", "01010"}},
+		{full, Torus, []string{"88888", "88888", "88888", "88888"}},
+		{full, Fixed, []string{"35553", "58885", "58885", "35553"}},
+		{full, Mirror, []string{"88888", "88888", "88888", "88888"}},
+	}
+	for _, tt := range tests {
+		for _, packed := range []bool{false, true} {
+			f := patternField(tt.rows)
+			if packed {
+				p := NewPackedField(f.h, f.w)
+				for i := 0; i < f.h; i++ {
+					for j := 0; j < f.w; j++ {
+						p.Set(i, j, f.Alive(i, j))
+					}
+				}
+				f = p
+			}
+			f.SetTopology(tt.topo)
+			if got := neighborCounts(f); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q %v packed %v: got %q, want %q", tt.rows, tt.topo, packed, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestGliderWall(t *testing.T) {
+	glider := []string{".o....", "..o...", "ooo...", "......", "......", "......"}
+	tests := []struct {
+		topo Topology
+		gens int
+		want []string
+	}{
+		// the glider crashes into the corner and leaves a block.
+		{Fixed, 12, []string{"......", "......", "......", "....o.", ".....o", "...ooo"}},
+		{Fixed, 30, []string{"......", "......", "......", "......", "....oo", "....oo"}},
+		// the glider moves across the edges and comes back in 24 generations.
+		{Torus, 16, []string{"o...oo", "......", "......", "......", ".....o", "o....."}},
+		{Torus, 24, glider},
+	}
+	for _, tt := range tests {
+		f := patternField(glider)
+		l, err := NewLife(f.h, f.w, f.cs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.SetTopology(tt.topo)
+		l.Run(tt.gens)
+		if got := fieldRows(l.cur); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v generation %d: got %q, want %q", tt.topo, tt.gens, got, tt.want)
+		}
+	}
+}
+
+func TestMirrorBlinker(t *testing.T) {
+	// the blinker at the wall behaves as if its mirror image is beyond the
+	// wall, forming a 3x2 rectangle.
+	f := patternField([]string{"....", "o...", "o...", "o...", "...."})
+	l, err := NewLife(f.h, f.w, f.cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetTopology(Mirror)
+	l.Next()
+	want := []string{"....", "o...", ".o..", "o...", "...."}
+	if got := fieldRows(l.cur); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMirrorTorus(t *testing.T) {
+	// a field with mirror edges is a quarter of the torus twice as large,
+	// holding the field and its images mirrored at the edges.
+	fields := []*Field{
+		patternField([]string{"ooo..", ".....", ".....", "....."}),
+		patternField([]string{".....", ".....", "....o", "....o", "....o"}),
+		randomField(9, 14, 0.3, 1, false),
+	}
+	for _, f := range fields {
+		mirror := lifeOf(f)
+		mirror.SetTopology(Mirror)
+		h, w := f.h, f.w
+		torus := lifeOf(NewField(2*h, 2*w))
+		for i := 0; i < h; i++ {
+			for j := 0; j < w; j++ {
+				b := f.get(i, j)
+				torus.cur.put(i, j, b)
+				torus.cur.put(2*h-1-i, j, b)
+				torus.cur.put(i, 2*w-1-j, b)
+				torus.cur.put(2*h-1-i, 2*w-1-j, b)
+			}
+		}
+		for g := 1; g <= 20; g++ {
+			mirror.Next()
+			torus.Next()
+			want := fieldRows(torus.cur)[:h]
+			for i := range want {
+				want[i] = want[i][:w]
+			}
+			if got := fieldRows(mirror.cur); !reflect.DeepEqual(got, want) {
+				t.Fatalf("%dx%d generation %d: got %q, want %q", h, w, g, got, want)
+			}
+		}
+	}
+}
+
+func TestShift(t *testing.T) {
+	block := []string{".....", "...oo", "...oo", "....."}
+	tests := []struct {
+		topo   Topology
+		dr, dc int
+		want   []string
+	}{
+		{Fixed, 0, 1, []string{".....", "....o", "....o", "....."}},
+		{Fixed, 0, 2, []string{".....", ".....", ".....", "....."}},
+		{Fixed, 2, -3, []string{".....", ".....", ".....", "oo..."}},
+		{Fixed, -3, 0, []string{".....", ".....", ".....", "....."}},
+		{Mirror, 0, 2, []string{".....", ".....", ".....", "....."}},
+		{Torus, 0, 1, []string{".....", "o...o", "o...o", "....."}},
+		{Torus, 0, 2, []string{".....", "oo...", "oo...", "....."}},
+		{Torus, -2, 0, []string{"...oo", ".....", ".....", "...oo"}},
+		{Torus, 2, -3, []string{"oo...", ".....", ".....", "oo..."}},
+		{Torus, 9, 10, []string{".....", ".....", "...oo", "...oo"}},
+	}
+	for _, tt := range tests {
+		f := patternField(block)
+		f.SetTopology(tt.topo)
+		f.Shift(tt.dr, tt.dc)
+		if got := fieldRows(f); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v shift (%d, %d): got %q, want %q", tt.topo, tt.dr, tt.dc, got, tt.want)
+		}
+	}
+}
+
+func TestSeeds(t *testing.T) {
+	for _, s := range []string{"B2/S", "S/B2", "/2"} {
+		r, err := ParseRule(s)
+		if err != nil {
+			t.Fatalf("ParseRule(%q): %v", s, err)
+		}
+		if got := r.String(); got != "B2/S" {
+			t.Errorf("ParseRule(%q) = %s, want B2/S", s, got)
+		}
+	}
+	for _, s := range []string{"B2/", "/S", "B2//S"} {
+		if _, err := ParseRule(s); err == nil {
+			t.Errorf("ParseRule(%q) succeeded", s)
+		}
+	}
+
+	seeds, _ := ParseRule("B2/S")
+	l, err := newLifeWithMargin(patternField([]string{"oo"}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetRule(seeds)
+	l.Next()
+	want := []string{"......", "..oo..", "......", "..oo..", "......"}
+	if got := fieldRows(l.cur); !reflect.DeepEqual(got, want) {
+		t.Errorf("domino: got %q, want %q", got, want)
+	}
+
+	// No live cell survives, however many neighbors it has.
+	l = lifeOf(randomField(30, 40, 0.3, 1, false))
+	l.SetRule(seeds)
+	for g := 0; g < 10; g++ {
+		prev := l.cur.Clone()
+		l.Next()
+		for i := 0; i < prev.h; i++ {
+			for j := 0; j < prev.w; j++ {
+				if prev.get(i, j) && l.cur.get(i, j) {
+					t.Fatalf("generation %d: cell (%d, %d) survived", g+1, i, j)
+				}
+			}
+		}
+	}
+}
+
+// writesRecorder records each call of Write, failing with err if not nil.
+type writesRecorder struct {
+	writes []string
+	err    error
+}
+
+func (w *writesRecorder) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestFprintBlinker(t *testing.T) {
+	l, err := NewLife(3, 3, [][]bool{{false, false, false}, {true, true, true}, {false, false, false}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetTopology(Fixed)
+	tests := []struct {
+		name   string
+		fprint func(w io.Writer) error
+		want   string
+	}{
+		{"Field.Fprint", l.cur.Fprint, "   \nooo\n   \n"},
+		{"Life.Fprint", l.Fprint, "---------- 0th generation\n   \nooo\n   \n"},
+		{"Life.Fprint", func(w io.Writer) error {
+			l.Next()
+			return l.Fprint(w)
+		}, "---------- 1th generation\n o \n o \n o \n"},
+		{"TerminalRenderer", func(w io.Writer) error {
+			return l.terminalRenderer(w).Render(l.cur, l.gen)
+		}, "\x1b[H---------- 1th generation\x1b[K\n o \x1b[K\n o \x1b[K\n o \x1b[K\n\x1b[J"},
+	}
+	for _, tt := range tests {
+		if tt.name == "TerminalRenderer" && !enableANSI(&writesRecorder{}) {
+			continue
+		}
+		w := &writesRecorder{}
+		if err := tt.fprint(w); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(w.writes) != 1 {
+			t.Errorf("%s: %d writes, want a write", tt.name, len(w.writes))
+		}
+		if got := strings.Join(w.writes, ""); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	errWrite := errors.New("write error")
+	for _, fprint := range []func(w io.Writer) error{l.cur.Fprint, l.Fprint} {
+		if err := fprint(&writesRecorder{err: errWrite}); err != errWrite {
+			t.Errorf("got error %v, want %v", err, errWrite)
+		}
+	}
+}
+
+func TestNewLifeFromText(t *testing.T) {
+	want := []string{".o.", ".o.", ".o."}
+	for _, text := range []string{
+		" o \n o \n o \n",
+		" o \r\n o \r\n o \r\n",
+		" o \n o \n o ",
+	} {
+		l, err := newLifeFromText(strings.NewReader(text))
+		if err != nil {
+			t.Fatalf("%q: %v", text, err)
+		}
+		if l.cur.w != 3 || l.cur.h != 3 {
+			t.Errorf("%q: got %dx%d field, want 3x3", text, l.cur.w, l.cur.h)
+		}
+		if got := fieldRows(l.cur); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %q, want %q", text, got, want)
+		}
+	}
+	if _, err := newLifeFromText(strings.NewReader(" o \n o\n o \n")); err == nil {
+		t.Error("ragged rows: got no error")
+	}
+}
+
+func TestNewLifeFromFileNoTrailingNewline(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{".o.\n..o\nooo", []string{".o.", "..o", "ooo"}},
+		{"...\r\nooo", []string{"...", "ooo"}},
+		{"oo", []string{"oo"}},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "init.txt")
+		if err := ioutil.WriteFile(path, []byte(tt.text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		l, err := NewLifeFromFile(path)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.text, err)
+		}
+		if got := fieldRows(l.cur); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNewLifeInitSize(t *testing.T) {
+	tests := []struct {
+		name string
+		h, w int
+		init [][]bool
+		ok   bool
+	}{
+		{name: "empty", h: 0, w: 0, init: [][]bool{}},
+		{name: "nil", h: 0, w: 0, init: nil},
+		{name: "empty for 2x2", h: 2, w: 2, init: [][]bool{}},
+		{name: "too few rows", h: 3, w: 2, init: [][]bool{{true, false}, {false, true}}},
+		{name: "too many rows", h: 1, w: 2, init: [][]bool{{true, false}, {false, true}}},
+		{name: "ragged first row", h: 2, w: 2, init: [][]bool{{true}, {false, true}}},
+		{name: "ragged last row", h: 3, w: 2, init: [][]bool{{true, false}, {false, true}, {true, false, true}}},
+		{name: "empty row", h: 2, w: 2, init: [][]bool{{true, false}, {}}},
+		{name: "2x3", h: 2, w: 3, init: [][]bool{{true, false, true}, {false, true, false}}, ok: true},
+	}
+	for _, tt := range tests {
+		l, err := NewLife(tt.h, tt.w, tt.init)
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("%s: NewLife(%d, %d) = nil error, want error", tt.name, tt.h, tt.w)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: NewLife(%d, %d) = %v", tt.name, tt.h, tt.w, err)
+			continue
+		}
+		if l.cur.h != tt.h || l.cur.w != tt.w {
+			t.Errorf("%s: got %dx%d field, want %dx%d", tt.name, l.cur.h, l.cur.w, tt.h, tt.w)
+		}
+	}
+}
+
+func TestNewLifeRaggedRowError(t *testing.T) {
+	_, err := NewLife(3, 2, [][]bool{{true, false}, {false}, {true}})
+	if err == nil || !strings.Contains(err.Error(), "row 1") {
+		t.Errorf("NewLife with ragged row 1 = %v, want error naming row 1", err)
+	}
+}
+
+func TestNextReusesBuffer(t *testing.T) {
+	gens, _ := ParseRule("B2/S/C4")
+	ltl, _ := ParseRule("R2,C0,M1,S5..9,B7..8,NM")
+	tests := []struct {
+		name       string
+		elementary bool
+		setup      func(l *Life)
+	}{
+		{name: "conway", setup: func(l *Life) {}},
+		{name: "generations", setup: func(l *Life) { l.SetRule(gens) }},
+		{name: "larger than life", setup: func(l *Life) { l.SetRule(ltl) }},
+		{name: "ages", setup: func(l *Life) { l.SetRenderMode(Color) }},
+		{name: "activity", setup: func(l *Life) { l.SetRenderMode(Heatmap) }},
+		{name: "history", setup: func(l *Life) { l.SetHistory(3) }},
+		{name: "elementary", elementary: true, setup: func(l *Life) { l.SetRenderMode(Color) }},
+		{name: "growth", setup: func(l *Life) {
+			l.SetTopology(Fixed)
+			l.SetGrowth(2, DefaultMaxCells)
+			l.SetHistory(2)
+		}},
+	}
+	for _, tt := range tests {
+		l, err := NewRandomLife(20, 30, 0.3, 1)
+		if tt.elementary {
+			l, err = NewElementaryLife(20, 30, 30)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.setup(l)
+		// ref calculates each generation into a new buffer.
+		ref := l.Clone()
+		for g := 0; g < 100; g++ {
+			l.Next()
+			ref = ref.Clone()
+			ref.next = ref.cur.blank()
+			ref.Next()
+			a, b := l.cur, ref.cur
+			if !a.Equal(b) || !reflect.DeepEqual(a.ages, b.ages) || !reflect.DeepEqual(a.heat, b.heat) ||
+				!reflect.DeepEqual(a.dying, b.dying) || a.top != b.top || a.left != b.left {
+				t.Fatalf("%s: generation %d differs", tt.name, l.gen)
+			}
+		}
+	}
+}
+
+func BenchmarkNextAllocs(b *testing.B) {
+	l, err := NewRandomLife(512, 512, 0.3, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	l.SetWorkers(1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Next()
+	}
+}
+
+func TestStepParallel(t *testing.T) {
+	rules := []string{"B3/S23", "B2/S/C3", "R2,C0,M1,S5..9,B7..8,NM"}
+	for _, rule := range rules {
+		r, err := ParseRule(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, topo := range []Topology{Torus, Fixed, Mirror} {
+			for _, workers := range []int{2, 3, 4, 7, 100} {
+				seq, err := NewRandomLife(37, 53, 0.3, int64(workers))
+				if err != nil {
+					t.Fatal(err)
+				}
+				seq.SetRule(r)
+				seq.SetTopology(topo)
+				seq.SetWorkers(1)
+				par := seq.Clone()
+				par.SetWorkers(workers)
+				for g := 0; g < 30; g++ {
+					wantB, wantD := seq.Step()
+					b, d := par.Step()
+					if !equalStates(par.cur, seq.cur) || b != wantB || d != wantD {
+						t.Fatalf("%v %v %d workers: generation %d differs from sequential one",
+							rule, topo, workers, seq.gen)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkStepParallel(b *testing.B) {
+	l, err := NewRandomLife(2048, 2048, 0.3, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			c := l.Clone()
+			c.SetWorkers(workers)
+			for i := 0; i < b.N; i++ {
+				// calculate whole field, not only the active region.
+				c.activeFor = nil
+				c.Step()
+			}
+		})
+	}
+}
+
+// fakeRenderer records generations rendered, and fails with err after
+// rendering limit generations if limit is positive.
+type fakeRenderer struct {
+	gens  []int
+	rows  [][]string
+	limit int
+	err   error
+}
+
+func (r *fakeRenderer) Render(f *Field, gen int) error {
+	if r.limit > 0 && len(r.gens) == r.limit {
+		return r.err
+	}
+	r.gens = append(r.gens, gen)
+	rows := make([]string, f.Height())
+	for i := range rows {
+		for j := 0; j < f.Width(); j++ {
+			if f.Alive(i, j) {
+				rows[i] += "o"
+			} else {
+				rows[i] += "."
+			}
+		}
+	}
+	r.rows = append(r.rows, rows)
+	return nil
+}
+
+func TestRunContextRenderer(t *testing.T) {
+	l, err := NewLifeFromPattern("blinker", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetTopology(Fixed)
+	r := &fakeRenderer{}
+	calls := 0
+	err = l.runContext(context.Background(), time.Millisecond, r, func(*Life) bool {
+		calls++
+		return calls < 3
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(r.gens, want) {
+		t.Errorf("rendered generations %v, want %v", r.gens, want)
+	}
+	horizontal := []string{".....", ".ooo.", "....."}
+	vertical := []string{"..o..", "..o..", "..o.."}
+	if want := [][]string{horizontal, vertical, horizontal}; !reflect.DeepEqual(r.rows, want) {
+		t.Errorf("rendered fields %q, want %q", r.rows, want)
+	}
+
+	errRender := errors.New("render error")
+	r = &fakeRenderer{limit: 2, err: errRender}
+	if err := l.runContext(context.Background(), time.Millisecond, r, nil); err != errRender {
+		t.Errorf("got error %v, want %v", err, errRender)
+	}
+	if want := []int{2, 3}; !reflect.DeepEqual(r.gens, want) {
+		t.Errorf("rendered generations %v, want %v", r.gens, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r = &fakeRenderer{}
+	if err := l.runContext(ctx, time.Millisecond, r, nil); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	if len(r.gens) != 0 {
+		t.Errorf("rendered generations %v after cancel", r.gens)
+	}
+}
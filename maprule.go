@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// mapLen is the length of base64 string of MAP rules without padding.
+const mapLen = 86
+
+// parseMAP parses MAP rule string of Golly, which is "MAP" followed by
+// base64 of 512 bits of the transition table of Moore neighborhood,
+// optionally padded with "==".
+func parseMAP(s string) (Rule, error) {
+	data := strings.TrimSuffix(s[len("MAP"):], "==")
+	if len(data) != mapLen {
+		return Rule{}, fmt.Errorf("rule %q: MAP rule must have %d characters of base64, got %d", s, mapLen, len(data))
+	}
+	b, err := base64.RawStdEncoding.DecodeString(data)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %v", s, err)
+	}
+	r := Rule{mapped: true}
+	copy(r.table[:], b)
+	return r, nil
+}
+
+// mapString returns the MAP rule in the notation parseMAP reads.
+func (r Rule) mapString() string {
+	return "MAP" + base64.RawStdEncoding.EncodeToString(r.table[:])
+}
+
+// mapIndex returns the index of the transition table of MAP rules for the
+// cell of r & c, which has bits of NW, N, NE, W, the cell, E, SW, S and SE
+// from the most significant bit.
+func (f *Field) mapIndex(r, c int) int {
+	k := 0
+	for i := -1; i <= 1; i++ {
+		for j := -1; j <= 1; j++ {
+			k <<= 1
+			if f.Alive(r+i, c+j) {
+				k |= 1
+			}
+		}
+	}
+	return k
+}
+
+// mapNext returns whether the cell of r & c will be alive in next
+// generation of MAP rule.
+func (f *Field) mapNext(r, c int) bool {
+	k := f.mapIndex(r, c)
+	return f.rule.table[k>>3]&(0x80>>uint(k&7)) != 0
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// conwayMAP is the MAP rule of Conway's Game of Life.
+const conwayMAP = "MAPARYXfhZofugWaH7oaIDogBZofuhogOiAaIDogIAAgAAWaH7oaIDogGiA6ICAAIAAaIDogIAAgACAAIAAAAAAAA"
+
+func TestMAPConway(t *testing.T) {
+	rule, err := ParseRule(conwayMAP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rule.String(); got != conwayMAP {
+		t.Errorf("String() = %q, want %q", got, conwayMAP)
+	}
+	if _, err := ParseRule(conwayMAP + "=="); err != nil {
+		t.Errorf("padded MAP rule: %v", err)
+	}
+	for _, topo := range []Topology{Torus, Fixed, Mirror} {
+		a := lifeOf(randomField(30, 40, 0.3, 1, false))
+		b := lifeOf(randomField(30, 40, 0.3, 1, false))
+		a.SetTopology(topo)
+		b.SetTopology(topo)
+		b.SetRule(rule)
+		for g := 0; g < 50; g++ {
+			if !a.cur.Equal(b.cur) {
+				t.Fatalf("%v: generation %d differs from B3/S23", topo, g)
+			}
+			a.Next()
+			b.Next()
+		}
+	}
+}
+
+func TestParseMAPError(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string // substring of the error
+	}{
+		{"MAP", "86 characters"},
+		{conwayMAP[:len(conwayMAP)-1], "86 characters"},
+		{conwayMAP + "A", "86 characters"},
+		{conwayMAP[:10] + "!" + conwayMAP[11:], "illegal base64"},
+		{conwayMAP[:10] + "=" + conwayMAP[11:], "illegal base64"},
+	}
+	for _, tt := range tests {
+		_, err := ParseRule(tt.rule)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseRule(%q) = %v, want error containing %q", tt.rule, err, tt.want)
+		}
+	}
+}
+
+func TestMAPWithNeighborhood(t *testing.T) {
+	rule, err := ParseRule(conwayMAP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []Neighborhood{Moore, VonNeumann, Hexagonal} {
+		if _, err := rule.WithNeighborhood(n); err == nil {
+			t.Errorf("WithNeighborhood(%v) of MAP rule succeeded", n)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+// Option configures Life made by New.
+type Option func(*Life)
+
+// New returns Life of h x w dead cells with Conway's rule on torus,
+// configured by opts in order. Negative sizes are treated as 0, and fields
+// of packedThreshold cells or more are packed into words.
+func New(h, w int, opts ...Option) *Life {
+	if h < 0 {
+		h = 0
+	}
+	if w < 0 {
+		w = 0
+	}
+	var cur *Field
+	if h*w >= packedThreshold {
+		cur = NewPackedField(h, w)
+	} else {
+		cur = NewField(h, w)
+	}
+	l := &Life{cur: cur, next: cur.blank()}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// WithRule sets the rule to calculate next generation.
+func WithRule(r Rule) Option {
+	return func(l *Life) { l.SetRule(r) }
+}
+
+// WithWrap sets Torus topology when wrap is true, and Fixed otherwise.
+func WithWrap(wrap bool) Option {
+	if wrap {
+		return WithTopology(Torus)
+	}
+	return WithTopology(Fixed)
+}
+
+// WithTopology sets how to treat outside of the field.
+func WithTopology(t Topology) Option {
+	return func(l *Life) { l.SetTopology(t) }
+}
+
+// WithInit sets cells of init alive. Cells outside of the field are dropped.
+func WithInit(init [][]bool) Option {
+	return func(l *Life) { l.cur.StampClip(init, 0, 0) }
+}
+
+// WithHistory keeps depth previous generations for Prev.
+func WithHistory(depth int) Option {
+	return func(l *Life) { l.SetHistory(depth) }
+}
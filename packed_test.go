@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// randomField returns a field of h x w cells where each cell is alive with
+// probability density, packed by NewPackedField if packed is true.
+func randomField(h, w int, density float64, seed int64, packed bool) *Field {
+	f := NewField(h, w)
+	if packed {
+		f = NewPackedField(h, w)
+	}
+	f.Randomize(density, rand.New(rand.NewSource(seed)))
+	return f
+}
+
+// lifeOf returns Life starting from f.
+func lifeOf(f *Field) *Life {
+	return &Life{cur: f, next: f.blank()}
+}
+
+func TestPackedField(t *testing.T) {
+	for _, topo := range []Topology{Torus, Fixed, Mirror} {
+		for _, size := range [][2]int{{1, 1}, {3, 64}, {70, 130}, {65, 128}} {
+			h, w := size[0], size[1]
+			a := lifeOf(randomField(h, w, 0.3, 1, false))
+			b := lifeOf(randomField(h, w, 0.3, 1, true))
+			a.SetTopology(topo)
+			b.SetTopology(topo)
+			for g := 0; g < 30; g++ {
+				if !a.cur.Equal(b.cur) {
+					t.Fatalf("%v %dx%d: generation %d differs", topo, h, w, g)
+				}
+				a.Next()
+				b.Next()
+			}
+		}
+	}
+}
+
+func BenchmarkNext(b *testing.B) {
+	for _, n := range []int{256, 1024, 4096} {
+		for _, packed := range []bool{false, true} {
+			b.Run(fmt.Sprintf("%d/packed=%v", n, packed), func(b *testing.B) {
+				l := lifeOf(randomField(n, n, 0.3, 1, packed))
+				l.SetWorkers(1)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					// calculate whole field, not only the active region.
+					l.activeFor = nil
+					l.Next()
+				}
+			})
+		}
+	}
+}
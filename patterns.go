@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// patternMargin is the number of dead cells around built-in pattern when
+// a field is sized to fit it.
+const patternMargin = 10
+
+// library holds built-in patterns as rows of '.' for dead cell and 'o' for
+// live cell.
+var library = map[string][]string{
+	"glider": {
+		".o.",
+		"..o",
+		"ooo",
+	},
+	"block": {
+		"oo",
+		"oo",
+	},
+	"blinker": {
+		"ooo",
+	},
+	"toad": {
+		".ooo",
+		"ooo.",
+	},
+	"beacon": {
+		"oo..",
+		"oo..",
+		"..oo",
+		"..oo",
+	},
+	"pulsar": {
+		"..ooo...ooo..",
+		".............",
+		"o....o.o....o",
+		"o....o.o....o",
+		"o....o.o....o",
+		"..ooo...ooo..",
+		".............",
+		"..ooo...ooo..",
+		"o....o.o....o",
+		"o....o.o....o",
+		"o....o.o....o",
+		".............",
+		"..ooo...ooo..",
+	},
+	"gosper-gun": {
+		"........................o...........",
+		"......................o.o...........",
+		"............oo......oo............oo",
+		"...........o...o....oo............oo",
+		"oo........o.....o...oo..............",
+		"oo........o...o.oo....o.o...........",
+		"..........o.....o.......o...........",
+		"...........o...o....................",
+		"............oo......................",
+	},
+	"r-pentomino": {
+		".oo",
+		"oo.",
+		".o.",
+	},
+	"acorn": {
+		".o.....",
+		"...o...",
+		"oo..ooo",
+	},
+	"lwss": {
+		".o..o",
+		"o....",
+		"o...o",
+		"oooo.",
+	},
+}
+
+// Patterns returns built-in patterns by name. Each call returns new fields,
+// so that callers can modify them freely.
+func Patterns() map[string]*Field {
+	ps := make(map[string]*Field, len(library))
+	for name, rows := range library {
+		ps[name] = patternField(rows)
+	}
+	return ps
+}
+
+// Pattern returns the cells of built-in pattern of name as rows of cells,
+// and reports whether the pattern exists. Each call returns new slices.
+func Pattern(name string) ([][]bool, bool) {
+	rows, ok := library[name]
+	if !ok {
+		return nil, false
+	}
+	return patternField(rows).cs, true
+}
+
+// PatternNames returns sorted names of built-in patterns.
+func PatternNames() []string {
+	names := make([]string, 0, len(library))
+	for name := range library {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewLifeFromPattern create new lifegame buffer holding the built-in pattern
+// of name, surrounded by margin dead cells on each side.
+func NewLifeFromPattern(name string, margin int) (*Life, error) {
+	rows, ok := library[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown pattern %q", name)
+	}
+	return newLifeWithMargin(patternField(rows), margin)
+}
+
+// newLifeWithMargin create new lifegame buffer holding the cells of p,
+// surrounded by margin dead cells on each side.
+func newLifeWithMargin(p *Field, margin int) (*Life, error) {
+	if margin < 0 {
+		return nil, fmt.Errorf("negative margin %d", margin)
+	}
+	h, w := p.h+2*margin, p.w+2*margin
+	init := make([][]bool, h)
+	for i := range init {
+		init[i] = make([]bool, w)
+	}
+	for i := 0; i < p.h; i++ {
+		for j := 0; j < p.w; j++ {
+			init[margin+i][margin+j] = p.get(i, j)
+		}
+	}
+	l, err := NewLife(h, w, init)
+	if err != nil {
+		return nil, err
+	}
+	l.SetRule(p.rule)
+	return l, nil
+}
+
+func patternField(rows []string) *Field {
+	f := NewField(len(rows), len(rows[0]))
+	for i, r := range rows {
+		for j, c := range r {
+			f.cs[i][j] = c == 'o'
+		}
+	}
+	return f
+}
+
+// Stamp sets live cells of pattern alive in the field, placing the top left
+// of pattern at (row, col). Cells already alive remain alive. It is an error
+// if pattern extends past the edges of the field.
+func (f *Field) Stamp(pattern [][]bool, row, col int) error {
+	for i, cells := range pattern {
+		if len(cells) == 0 {
+			continue
+		}
+		if row+i < 0 || row+i >= f.h || col < 0 || col+len(cells) > f.w {
+			return fmt.Errorf("pattern at (%d, %d) extends past %dx%d field", row, col, f.w, f.h)
+		}
+	}
+	f.StampClip(pattern, row, col)
+	return nil
+}
+
+// StampClip is like Stamp but drops cells of pattern outside of the field.
+func (f *Field) StampClip(pattern [][]bool, row, col int) {
+	for i, cells := range pattern {
+		r := row + i
+		if r < 0 || r >= f.h {
+			continue
+		}
+		for j, b := range cells {
+			c := col + j
+			if !b || c < 0 || c >= f.w || f.get(r, c) {
+				continue
+			}
+			f.setCell(r, c, true)
+		}
+	}
+}
+
+// patternSize returns the number of rows and the longest row of p.
+func patternSize(p [][]bool) (h, w int) {
+	for _, cells := range p {
+		if len(cells) > w {
+			w = len(cells)
+		}
+	}
+	return len(p), w
+}
+
+// newPattern returns h x w pattern with all cells dead.
+func newPattern(h, w int) [][]bool {
+	p := make([][]bool, h)
+	for i := range p {
+		p[i] = make([]bool, w)
+	}
+	return p
+}
+
+// RotatePattern returns p rotated by 90 degrees clockwise. Rows of h x w
+// pattern become columns of w x h pattern. p is not modified.
+func RotatePattern(p [][]bool) [][]bool {
+	h, w := patternSize(p)
+	q := newPattern(w, h)
+	for i, cells := range p {
+		for j, b := range cells {
+			q[j][h-1-i] = b
+		}
+	}
+	return q
+}
+
+// FlipHorizontal returns p mirrored left to right. p is not modified.
+func FlipHorizontal(p [][]bool) [][]bool {
+	h, w := patternSize(p)
+	q := newPattern(h, w)
+	for i, cells := range p {
+		for j, b := range cells {
+			q[i][w-1-j] = b
+		}
+	}
+	return q
+}
+
+// FlipVertical returns p mirrored top to bottom. p is not modified.
+func FlipVertical(p [][]bool) [][]bool {
+	h, w := patternSize(p)
+	q := newPattern(h, w)
+	for i, cells := range p {
+		copy(q[h-1-i], cells)
+	}
+	return q
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestStampClipDyingCell(t *testing.T) {
+	// live cells survive with any number of neighbors, and die in 2
+	// generations only when killed.
+	rule, err := ParseRule("B/S012345678/C4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(3, 3)
+	l.SetRule(rule)
+	l.cur.dying[1][1] = 1
+	l.cur.StampClip([][]bool{{true}}, 1, 1)
+	if s := l.cur.State(1, 1); s != 1 {
+		t.Fatalf("stamped cell has state %d, want 1", s)
+	}
+	l.Next()
+	if s := l.cur.State(1, 1); s != 1 {
+		t.Errorf("stamped cell has state %d after a generation, want 1", s)
+	}
+}
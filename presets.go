@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// rulePresets holds well-known rules by their names normalized by ruleKey.
+var rulePresets = map[string]string{
+	"life":             "B3/S23",
+	"highlife":         "B36/S23",
+	"seeds":            "B2/S",
+	"daynight":         "B3678/S34678",
+	"maze":             "B3/S12345",
+	"replicator":       "B1357/S1357",
+	"2x2":              "B36/S125",
+	"lifewithoutdeath": "B3/S012345678",
+	"briansbrain":      "B2/S/C3",
+	"starwars":         "B2/S345/C4",
+}
+
+// ruleKey normalizes name of rule presets, so that "Day & Night" and
+// "daynight" are the same.
+func ruleKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// RulePresets returns well-known rules by their names.
+func RulePresets() map[string]Rule {
+	rs := make(map[string]Rule, len(rulePresets))
+	for name, s := range rulePresets {
+		rs[name], _ = ParseRule(s)
+	}
+	return rs
+}
+
+// RuleNames returns sorted names of rule presets.
+func RuleNames() []string {
+	names := make([]string, 0, len(rulePresets))
+	for name := range rulePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupRule returns the rule preset of name, which is case insensitive and
+// ignores spaces and punctuation, or the rule of rule string s otherwise.
+func LookupRule(s string) (Rule, error) {
+	key := ruleKey(s)
+	if rs, ok := rulePresets[key]; ok {
+		return ParseRule(rs)
+	}
+	if r, err := ParseRule(s); err == nil || strings.ContainsAny(s, "/,") {
+		return r, err
+	}
+	best, dist := "", -1
+	for _, name := range RuleNames() {
+		if d := editDistance(key, name); dist < 0 || d < dist {
+			best, dist = name, d
+		}
+	}
+	return Rule{}, fmt.Errorf("unknown rule %q, did you mean %q?", s, best)
+}
+
+// editDistance returns Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			d := prev[j-1]
+			if a[i-1] != b[j-1] {
+				d++
+			}
+			if prev[j]+1 < d {
+				d = prev[j] + 1
+			}
+			if cur[j-1]+1 < d {
+				d = cur[j-1] + 1
+			}
+			cur[j] = d
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
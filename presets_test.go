@@ -0,0 +1,200 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stateLife returns Life under rule with cells of rows, in the form of
+// fieldStates, surrounded by margin dead cells on each side.
+func stateLife(t *testing.T, rule Rule, rows []string, margin int) *Life {
+	t.Helper()
+	p := NewField(len(rows), len(rows[0]))
+	for i, r := range rows {
+		for j, c := range r {
+			p.cs[i][j] = c != '.'
+		}
+	}
+	l, err := newLifeWithMargin(p, margin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetRule(rule)
+	for i, r := range rows {
+		for j, c := range r {
+			if c >= '2' && c <= '9' {
+				l.cur.put(margin+i, margin+j, false)
+				l.cur.dying[margin+i][margin+j] = uint8(c - '1')
+			}
+		}
+	}
+	return l
+}
+
+// fieldStates returns rows of f as strings like fieldRows, but with digits
+// of State for dying cells of Generations rules.
+func fieldStates(f *Field) []string {
+	rows := make([]string, f.h)
+	for i := range rows {
+		var b strings.Builder
+		for j := 0; j < f.w; j++ {
+			switch s := f.State(i, j); s {
+			case 0:
+				b.WriteByte('.')
+			case 1:
+				b.WriteByte('o')
+			default:
+				b.WriteByte(byte('0' + s))
+			}
+		}
+		rows[i] = b.String()
+	}
+	return rows
+}
+
+func TestRulePresets(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []string
+		margin int
+		gens   int
+		want   []string
+	}{
+		// Blinker oscillates with period 2.
+		{"Life", []string{"ooo"}, 1, 1, []string{"..o..", "..o..", "..o.."}},
+		{"Life", []string{"ooo"}, 1, 2, []string{".....", ".ooo.", "....."}},
+		// Replicator copies itself in 12 generations.
+		{"HighLife", []string{"..ooo", ".o..o", "o...o", "o..o.", "ooo.."}, 3, 12, []string{
+			"...........",
+			"...ooo.....",
+			"..o..o.....",
+			".o...o.....",
+			".o..o......",
+			".ooo...ooo.",
+			"......o..o.",
+			".....o...o.",
+			".....o..o..",
+			".....ooo...",
+			"...........",
+		}},
+		// Every live cell dies, and a domino gives birth to two dominoes.
+		{"Seeds", []string{"oo"}, 1, 1, []string{".oo.", "....", ".oo."}},
+		// Oscillator of period 2.
+		{"Day & Night", []string{".o.", ".oo", "o.."}, 1, 1, []string{".....", "...o.", ".oo..", "..o..", "....."}},
+		{"Day & Night", []string{".o.", ".oo", "o.."}, 1, 2, []string{".....", "..o..", "..oo.", ".o...", "....."}},
+		// Cells hardly die, and grow into corridors.
+		{"Maze", []string{"ooo"}, 3, 4, []string{
+			".........",
+			"...ooo...",
+			"..ooooo..",
+			"..oo.oo..",
+			"..ooooo..",
+			"...ooo...",
+			".........",
+		}},
+		// Every pattern has 8 copies of itself after 2^n generations.
+		{"Replicator", []string{"o"}, 4, 4, []string{
+			"o...o...o",
+			".........",
+			".........",
+			".........",
+			"o.......o",
+			".........",
+			".........",
+			".........",
+			"o...o...o",
+		}},
+		// Oscillator of period 2.
+		{"2x2", []string{".oo", "..o", "oo."}, 1, 1, []string{".....", "..oo.", ".o...", ".oo..", "....."}},
+		{"2x2", []string{".oo", "..o", "oo."}, 1, 2, []string{".....", "..oo.", "...o.", ".oo..", "....."}},
+		// Live cells never die.
+		{"Life without Death", []string{"ooo"}, 2, 1, []string{".......", "...o...", "..ooo..", "...o...", "......."}},
+		// Spaceship moving a cell each generation.
+		{"Brian's Brain", []string{"22", "oo"}, 1, 1, []string{"....", "....", ".22.", ".oo."}},
+		{"Star Wars", []string{"32o", "32o"}, 1, 1, []string{".....", "..32o", "..32o", "....."}},
+	}
+	for _, tt := range tests {
+		rule, err := LookupRule(tt.name)
+		if err != nil {
+			t.Fatalf("LookupRule(%q): %v", tt.name, err)
+		}
+		l := stateLife(t, rule, tt.rows, tt.margin)
+		l.SetTopology(Fixed)
+		for g := 0; g < tt.gens; g++ {
+			l.Next()
+		}
+		if got := fieldStates(l.cur); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: generation %d got %q, want %q", tt.name, tt.gens, got, tt.want)
+		}
+	}
+}
+
+func TestRulePresetNames(t *testing.T) {
+	presets := RulePresets()
+	for _, name := range RuleNames() {
+		rule, err := LookupRule(name)
+		if err != nil {
+			t.Errorf("LookupRule(%q): %v", name, err)
+			continue
+		}
+		if got, want := rule.String(), presets[name].String(); got != want {
+			t.Errorf("LookupRule(%q) = %s, want %s", name, got, want)
+		}
+	}
+	if _, err := LookupRule("day-nite"); err == nil || !strings.Contains(err.Error(), `"daynight"`) {
+		t.Errorf("LookupRule(%q) = %v, want error suggesting %q", "day-nite", err, "daynight")
+	}
+}
+
+func TestDayNightSymmetry(t *testing.T) {
+	rule, err := LookupRule("daynight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Day & Night treats live and dead cells the same way, so that
+	// inverting all cells commutes with calculating next generation.
+	a := lifeOf(randomField(30, 40, 0.3, 1, false))
+	b := lifeOf(randomField(30, 40, 0.3, 1, false))
+	a.SetRule(rule)
+	b.SetRule(rule)
+	invert(b.cur)
+	for g := 0; g < 30; g++ {
+		a.Next()
+		b.Next()
+		invert(b.cur)
+		if !a.cur.Equal(b.cur) {
+			t.Fatalf("generation %d of inverted field is not inverted", g+1)
+		}
+		invert(b.cur)
+	}
+}
+
+func TestLifeWithoutDeath(t *testing.T) {
+	rule, err := LookupRule("lifewithoutdeath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := lifeOf(randomField(30, 40, 0.1, 1, false))
+	l.SetRule(rule)
+	for g := 0; g < 30; g++ {
+		prev := l.cur.Clone()
+		l.Next()
+		for i := 0; i < prev.h; i++ {
+			for j := 0; j < prev.w; j++ {
+				if prev.get(i, j) && !l.cur.get(i, j) {
+					t.Fatalf("generation %d: cell (%d, %d) died", g+1, i, j)
+				}
+			}
+		}
+	}
+}
+
+// invert flips all cells of f.
+func invert(f *Field) {
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			f.put(i, j, !f.get(i, j))
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// NewRandomLife create new lifegame buffer of h x w cells where each cell is
+// alive with probability density. The same seed gives the same field.
+func NewRandomLife(h, w int, density float64, seed int64) (*Life, error) {
+	if !(density >= 0 && density <= 1) {
+		return nil, fmt.Errorf("density %v is out of range [0, 1]", density)
+	}
+	init := make([][]bool, h)
+	for i := range init {
+		init[i] = make([]bool, w)
+	}
+	l, err := NewLife(h, w, init)
+	if err != nil {
+		return nil, err
+	}
+	l.cur.Randomize(density, rand.New(rand.NewSource(seed)))
+	return l, nil
+}
+
+// Randomize sets each cell of the field alive with probability density,
+// which is clamped to [0, 1], using random numbers from r.
+func (f *Field) Randomize(density float64, r *rand.Rand) {
+	if density < 0 {
+		density = 0
+	}
+	if density > 1 {
+		density = 1
+	}
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			f.setCell(i, j, r.Float64() < density)
+		}
+	}
+}
+
+// parseSize parses size of field such as "40x80" and returns height and width.
+func parseSize(s string) (h, w int, err error) {
+	if _, err := fmt.Sscanf(s, "%dx%d", &h, &w); err != nil || h <= 0 || w <= 0 {
+		return 0, 0, fmt.Errorf("invalid size %q, want HEIGHTxWIDTH", s)
+	}
+	return h, w, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// RenderMode is the way to display fields as text.
+type RenderMode int
+
+const (
+	// ASCII displays each cell as a character.
+	ASCII RenderMode = iota
+	// HalfBlock packs two rows of cells into a character with Unicode
+	// half blocks, which doubles the visible height.
+	HalfBlock
+	// Braille packs 2 x 4 cells into a character with Unicode braille
+	// patterns.
+	Braille
+	// Color displays each cell as a character colored by its age with ANSI
+	// 256 colors.
+	Color
+	// Heatmap displays each cell as a character by how often it has been
+	// alive recently, so that moving patterns leave trails.
+	Heatmap
+)
+
+var renderModeNames = map[RenderMode]string{
+	ASCII:     "ascii",
+	HalfBlock: "halfblock",
+	Braille:   "braille",
+	Color:     "color",
+	Heatmap:   "heatmap",
+}
+
+// String returns the name of the render mode.
+func (m RenderMode) String() string {
+	if name, ok := renderModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("RenderMode(%d)", int(m))
+}
+
+// parseRenderMode returns the render mode of name.
+func parseRenderMode(name string) (RenderMode, error) {
+	for m, n := range renderModeNames {
+		if n == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown render mode %q", name)
+}
+
+// FprintMode display one generation status to w in render mode m.
+// The whole field is written at once.
+func (f *Field) FprintMode(w io.Writer, m RenderMode) error {
+	var buf bytes.Buffer
+	f.render(&buf, m, DefaultGlyphs)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// PrintHalfBlock display one generation status to w in HalfBlock render
+// mode, two rows of cells in a line.
+func (f *Field) PrintHalfBlock(w io.Writer) error {
+	return f.FprintMode(w, HalfBlock)
+}
+
+// PrintBraille display one generation status to w in Braille render mode,
+// 2 x 4 cells in a character.
+func (f *Field) PrintBraille(w io.Writer) error {
+	return f.FprintMode(w, Braille)
+}
+
+// PrintColored display one generation status to w in Color render mode.
+// Cells are colored by age only when ages are tracked, see Field.Age.
+func (f *Field) PrintColored(w io.Writer) error {
+	return f.FprintMode(w, Color)
+}
+
+// render appends the field in render mode m to buf, using glyphs g for
+// cells in ASCII and Color modes.
+func (f *Field) render(buf *bytes.Buffer, m RenderMode, g Glyphs) {
+	switch m {
+	case HalfBlock:
+		f.renderHalfBlock(buf)
+	case Braille:
+		f.renderBraille(buf)
+	case Color:
+		f.renderColor(buf, g)
+	case Heatmap:
+		f.renderHeatmap(buf)
+	default:
+		if f.dying != nil {
+			f.renderGenerations(buf, g)
+			return
+		}
+		if f.rule.nbhd == Hexagonal {
+			f.renderHex(buf, g)
+			return
+		}
+		f.renderText(buf, g.Alive, g.Dead)
+	}
+}
+
+// halfBlocks is indexed by the states of the upper and lower cells.
+var halfBlocks = [4]rune{' ', '▀', '▄', '█'}
+
+// renderHalfBlock appends two rows of cells in a line to buf. The row below
+// the last row of fields of odd height is treated as dead.
+func (f *Field) renderHalfBlock(buf *bytes.Buffer) {
+	for i := 0; i < f.h; i += 2 {
+		for j := 0; j < f.w; j++ {
+			k := 0
+			if f.get(i, j) {
+				k |= 1
+			}
+			if i+1 < f.h && f.get(i+1, j) {
+				k |= 2
+			}
+			buf.WriteRune(halfBlocks[k])
+		}
+		buf.WriteByte('\n')
+	}
+}
+
+// brailleDots is the bit of braille pattern for each dot, indexed by row
+// and column in 4 x 2 cells. Dots 1 to 8 are bits 0 to 7.
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// brailleBlank is the braille pattern with no dots.
+const brailleBlank = 0x2800
+
+// renderBraille appends 2 x 4 cells in a character to buf. Cells beyond the
+// edges of the field are treated as dead.
+func (f *Field) renderBraille(buf *bytes.Buffer) {
+	for i := 0; i < f.h; i += 4 {
+		for j := 0; j < f.w; j += 2 {
+			buf.WriteRune(f.braille(i, j))
+		}
+		buf.WriteByte('\n')
+	}
+}
+
+// braille returns braille pattern of 2 x 4 cells from (r, c).
+func (f *Field) braille(r, c int) rune {
+	ch := rune(brailleBlank)
+	for i := 0; i < 4 && r+i < f.h; i++ {
+		for j := 0; j < 2 && c+j < f.w; j++ {
+			if f.get(r+i, c+j) {
+				ch |= brailleDots[i][j]
+			}
+		}
+	}
+	return ch
+}
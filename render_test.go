@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestBraille(t *testing.T) {
+	// Unicode numbers the dots of a braille cell 1 to 8 in this order of
+	// row and column.
+	dots := [8][2]int{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {1, 1}, {2, 1}, {3, 0}, {3, 1}}
+	for bits := 0; bits < 256; bits++ {
+		f := NewField(4, 2)
+		for k, d := range dots {
+			if bits&(1<<uint(k)) != 0 {
+				f.Set(d[0], d[1], true)
+			}
+		}
+		want := rune(0x2800 + bits)
+		if got := f.braille(0, 0); got != want {
+			t.Errorf("dots %08b: got %U, want %U", bits, got, want)
+		}
+		var buf bytes.Buffer
+		if err := f.PrintBraille(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if !utf8.Valid(buf.Bytes()) {
+			t.Fatalf("dots %08b: invalid UTF-8 %q", bits, buf.Bytes())
+		}
+		if got, want := buf.String(), string(want)+"\n"; got != want {
+			t.Errorf("dots %08b: got %q, want %q", bits, got, want)
+		}
+	}
+}
+
+func TestBrailleRaggedEdges(t *testing.T) {
+	tests := []struct {
+		rows []string
+		want string
+	}{
+		{[]string{"o"}, "⠁\n"},
+		{[]string{"ooo"}, "⠉⠁\n"},
+		{[]string{"o", "o", "o", "o", "o"}, "⡇\n⠁\n"},
+		{[]string{"ooo", "ooo", "ooo", "ooo", "ooo"}, "⣿⡇\n⠉⠁\n"},
+		{[]string{"...", "...", "...", "...", "..o"}, "⠀⠀\n⠀⠁\n"},
+	}
+	for _, tt := range tests {
+		f := patternField(tt.rows)
+		var buf bytes.Buffer
+		if err := f.PrintBraille(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.rows, got, tt.want)
+		}
+	}
+}
+
+func TestHalfBlock(t *testing.T) {
+	tests := []struct {
+		rows []string
+		want string
+	}{
+		{[]string{"o.o.", "oo.."}, "█▄▀ \n"},
+		{[]string{"..", ".."}, "  \n"},
+		{[]string{"o.", ".o"}, "▀▄\n"},
+		{[]string{"oo", "oo", "o."}, "██\n▀ \n"},
+		{[]string{"o"}, "▀\n"},
+		{[]string{".o", "o.", "oo"}, "▄▀\n▀▀\n"},
+	}
+	for _, tt := range tests {
+		f := patternField(tt.rows)
+		var buf bytes.Buffer
+		if err := f.PrintHalfBlock(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.rows, got, tt.want)
+		}
+	}
+}
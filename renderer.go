@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// Renderer displays generations of lifegame.
+type Renderer interface {
+	// Render displays the field of generation gen.
+	Render(f *Field, gen int) error
+}
+
+// TextRenderer writes each generation as text following the header line.
+type TextRenderer struct {
+	Glyphs Glyphs             // characters of cells, which must be valid
+	Header *template.Template // header line, or nil for no header
+	Border bool               // whether to draw a border around the field
+	w      io.Writer
+	mode   RenderMode
+}
+
+// NewTextRenderer returns a renderer writing generations to w in render mode m.
+func NewTextRenderer(w io.Writer, m RenderMode) *TextRenderer {
+	return &TextRenderer{Glyphs: DefaultGlyphs, Header: DefaultHeader, w: w, mode: m}
+}
+
+// Render writes the field of generation gen.
+func (r *TextRenderer) Render(f *Field, gen int) error {
+	var buf bytes.Buffer
+	if err := renderGeneration(&buf, f, gen, r.mode, r.Glyphs, r.Header, r.Border); err != nil {
+		return err
+	}
+	_, err := r.w.Write(buf.Bytes())
+	return err
+}
+
+// renderGeneration appends the header line and the field of generation gen
+// to buf, drawing a border around the field if border is true.
+func renderGeneration(buf *bytes.Buffer, f *Field, gen int, m RenderMode, g Glyphs, header *template.Template, border bool) error {
+	if err := renderHeader(buf, header, f, gen, ""); err != nil {
+		return err
+	}
+	if !border {
+		f.render(buf, m, g)
+		return nil
+	}
+	var field bytes.Buffer
+	f.render(&field, m, g)
+	buf.Write(drawBorder(field.Bytes()))
+	return nil
+}
+
+// drawBorder returns lines of text surrounded by a border of box drawing
+// characters. Lines are padded to the widest one in terminal columns, where
+// ANSI escape sequences take no columns and wide characters take two.
+func drawBorder(text []byte) []byte {
+	lines := bytes.SplitAfter(text, []byte("\n"))
+	if n := len(lines); n > 0 && len(lines[n-1]) == 0 {
+		lines = lines[:n-1]
+	}
+	widths := make([]int, len(lines))
+	width := 0
+	for i, line := range lines {
+		lines[i] = bytes.TrimSuffix(line, []byte("\n"))
+		widths[i] = textWidth(lines[i])
+		if widths[i] > width {
+			width = widths[i]
+		}
+	}
+	var buf bytes.Buffer
+	edge := strings.Repeat("─", width)
+	buf.WriteString("┌" + edge + "┐\n")
+	for i, line := range lines {
+		buf.WriteString("│")
+		buf.Write(line)
+		buf.WriteString(strings.Repeat(" ", width-widths[i]) + "│\n")
+	}
+	buf.WriteString("└" + edge + "┘\n")
+	return buf.Bytes()
+}
+
+// textWidth returns the number of terminal columns which line takes.
+func textWidth(line []byte) int {
+	n := 0
+	for len(line) > 0 {
+		if i := csiLen(line); i > 0 {
+			line = line[i:]
+			continue
+		}
+		r, size := utf8.DecodeRune(line)
+		line = line[size:]
+		if isWide(r) {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// ANSI escape sequences to redraw the screen without clearing it, which
+// avoids flicker: move the cursor home, erase the rest of each line and
+// erase below the last line.
+const (
+	ansiHome      = "\x1b[H"
+	ansiEraseLine = "\x1b[K"
+	ansiEraseDown = "\x1b[J"
+)
+
+// TerminalRenderer redraws the terminal with each generation.
+type TerminalRenderer struct {
+	Glyphs Glyphs             // characters of cells, which must be valid
+	Header *template.Template // header line, or nil for no header
+	Border bool               // whether to draw a border around the field
+	w      io.Writer
+	mode   RenderMode
+}
+
+// NewTerminalRenderer returns a renderer redrawing the terminal which w is
+// connected to in render mode m.
+func NewTerminalRenderer(w io.Writer, m RenderMode) *TerminalRenderer {
+	return &TerminalRenderer{Glyphs: DefaultGlyphs, Header: DefaultHeader, w: w, mode: m}
+}
+
+// Render redraws the terminal with the field of generation gen.
+func (r *TerminalRenderer) Render(f *Field, gen int) error {
+	var buf bytes.Buffer
+	if err := renderGeneration(&buf, f, gen, r.mode, r.Glyphs, r.Header, r.Border); err != nil {
+		return err
+	}
+	return redraw(r.w, buf.Bytes())
+}
+
+// redraw redraws the terminal which w is connected to with text.
+func redraw(w io.Writer, text []byte) error {
+	if !enableANSI(w) {
+		clearScreen(w)
+		_, err := w.Write(text)
+		return err
+	}
+	_, err := w.Write(redrawFrame(text))
+	return err
+}
+
+// redrawFrame returns escape sequences to redraw the screen with text.
+func redrawFrame(text []byte) []byte {
+	frame := bytes.Replace(text, []byte("\n"), []byte(ansiEraseLine+"\n"), -1)
+	frame = append([]byte(ansiHome), frame...)
+	return append(frame, ansiEraseDown...)
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package main
+
+import "os"
+
+// notifyResize does nothing, as there is no signal of terminal resize, e.g.
+// on Windows console.
+func notifyResize(c chan<- os.Signal) {}
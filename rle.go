@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NewLifeFromRLE create new lifegame buffer from Run Length Encoded pattern.
+// See details of the format.
+//
+//	http://www.conwaylife.com/wiki/Run_Length_Encoded
+func NewLifeFromRLE(r io.Reader) (*Life, error) {
+	s := bufio.NewScanner(r)
+
+	// header line
+	var h, w int
+	rule := Conway
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var err error
+		h, w, rule, err = parseRLEHeader(line)
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if h == 0 {
+		return nil, errors.New("rle: header line not found")
+	}
+	if h > maxPatternCells/w {
+		return nil, fmt.Errorf("rle: pattern of %d x %d cells exceeds %d cells", w, h, maxPatternCells)
+	}
+
+	init := make([][]bool, h)
+	for i := range init {
+		init[i] = make([]bool, w)
+	}
+
+	// body. runs may wrap across lines, so the run count is kept between lines.
+	row, col, n := 0, 0, 0
+	counted, done := false, false
+	for !done && s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		for _, c := range line {
+			switch {
+			case c >= '0' && c <= '9':
+				n = n*10 + int(c-'0')
+				counted = true
+				continue
+			case c == ' ' || c == '\t':
+				continue
+			}
+			if counted && n == 0 {
+				return nil, fmt.Errorf("rle: run count 0 in row %d", row+1)
+			}
+			if n == 0 {
+				n = 1
+			}
+			switch c {
+			case 'b', 'o':
+				if row >= h {
+					return nil, fmt.Errorf("rle: row %d exceeds height %d", row+1, h)
+				}
+				if col+n > w {
+					return nil, fmt.Errorf("rle: row %d has %d cells, exceeds width %d", row+1, col+n, w)
+				}
+				for i := 0; i < n; i++ {
+					init[row][col+i] = c == 'o'
+				}
+				col += n
+			case '$':
+				row += n
+				col = 0
+			case '!':
+				if counted {
+					return nil, fmt.Errorf("rle: run count %d before terminator in row %d", n, row+1)
+				}
+				done = true
+			default:
+				return nil, fmt.Errorf("rle: unexpected character %q in row %d", c, row+1)
+			}
+			if done {
+				break
+			}
+			n, counted = 0, false
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if counted {
+		return nil, fmt.Errorf("rle: run count %d in row %d is not followed by a tag", n, row+1)
+	}
+	l, err := NewLife(h, w, init)
+	if err != nil {
+		return nil, err
+	}
+	l.SetRule(rule)
+	return l, nil
+}
+
+// parseRLEHeader parses "x = N, y = M, rule = B3/S23" and returns height,
+// width and rule. The rule defaults to Conway's when it is omitted.
+func parseRLEHeader(line string) (h, w int, rule Rule, err error) {
+	rule = Conway
+	kvs := strings.Split(line, ",")
+	for k, kv := range kvs {
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			return 0, 0, Rule{}, fmt.Errorf("rle: malformed header %q", line)
+		}
+		key := strings.TrimSpace(kv[:i])
+		val := strings.TrimSpace(kv[i+1:])
+		if key == "rule" {
+			// rules of Larger than Life contain commas, so the rule lasts
+			// to the end of the line.
+			val = strings.TrimSpace(strings.Join(kvs[k:], ",")[i+1:])
+		}
+		switch key {
+		case "x":
+			w, err = strconv.Atoi(val)
+		case "y":
+			h, err = strconv.Atoi(val)
+		case "rule":
+			rule, err = ParseRule(val)
+		}
+		if err != nil {
+			return 0, 0, Rule{}, fmt.Errorf("rle: malformed header %q: %v", line, err)
+		}
+		if key == "rule" {
+			break
+		}
+	}
+	if h <= 0 || w <= 0 {
+		return 0, 0, Rule{}, fmt.Errorf("rle: header %q must declare positive x and y", line)
+	}
+	return h, w, rule, nil
+}
+
+// rleLineLen is the maximum length of lines written by WriteRLE.
+const rleLineLen = 70
+
+// WriteRLE writes the field as Run Length Encoded pattern.
+// Trailing dead cells in each row and trailing empty rows are omitted.
+func (f *Field) WriteRLE(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "x = %d, y = %d, rule = %v\n", f.w, f.h, f.rule)
+
+	linelen := 0
+	emit := func(n int, tag byte) {
+		token := string(tag)
+		if n > 1 {
+			token = strconv.Itoa(n) + token
+		}
+		if linelen+len(token) > rleLineLen {
+			bw.WriteByte('\n')
+			linelen = 0
+		}
+		bw.WriteString(token)
+		linelen += len(token)
+	}
+
+	last := -1 // last row which has live cells
+	for i := 0; i < f.h; i++ {
+		end := f.w
+		for end > 0 && !f.get(i, end-1) {
+			end--
+		}
+		if end == 0 {
+			continue
+		}
+		if last >= 0 {
+			emit(i-last, '$')
+		} else if i > 0 {
+			emit(i, '$')
+		}
+		last = i
+		for j := 0; j < end; {
+			alive := f.get(i, j)
+			k := j
+			for k < end && f.get(i, k) == alive {
+				k++
+			}
+			if alive {
+				emit(k-j, 'o')
+			} else {
+				emit(k-j, 'b')
+			}
+			j = k
+		}
+	}
+	emit(1, '!')
+	bw.WriteByte('\n')
+	return bw.Flush()
+}
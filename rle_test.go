@@ -0,0 +1,199 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const gliderRLE = `#N Glider
+#C The smallest spaceship.
+x = 3, y = 3, rule = B3/S23
+bob$2bo$3o!
+`
+
+const gosperGunRLE = `#N Gosper glider gun
+x = 36, y = 9, rule = B3/S23
+24bo$22bobo$12b2o6b2o12b2o$11bo3bo4b2o12b2o$2o8bo5bo3b2o$2o8bo3bob2o4b
+obo$10bo5bo7bo$11bo3bo$12b2o!
+`
+
+func TestNewLifeFromRLE(t *testing.T) {
+	tests := []struct {
+		name    string
+		rle     string
+		pattern string
+	}{
+		{name: "glider", rle: gliderRLE, pattern: "glider"},
+		{name: "gosper gun", rle: gosperGunRLE, pattern: "gosper-gun"},
+		{
+			name:    "run wrapped across lines",
+			rle:     "x = 3, y = 3\nbo$2\nbo$3\no!\n",
+			pattern: "glider",
+		},
+	}
+	for _, tt := range tests {
+		l, err := NewLifeFromRLE(strings.NewReader(tt.rle))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if want := patternField(library[tt.pattern]); !l.cur.Equal(want) {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name,
+				strings.Join(fieldRows(l.cur), "\n"), strings.Join(fieldRows(want), "\n"))
+		}
+		if l.Rule() != Conway {
+			t.Errorf("%s: rule %v, want %v", tt.name, l.Rule(), Conway)
+		}
+	}
+}
+
+func TestNewLifeFromRLEError(t *testing.T) {
+	for _, rle := range []string{
+		"",
+		"#C no header\n",
+		"x = 0, y = 3\n!",
+		"x = 3\n3o!",
+		"x = 3, y = 3, rule = B9/S23\n3o!",
+		"x = 2, y = 1\n3o!",
+		"x = 3, y = 1\n3o$3o!",
+		"x = 3, y = 1\n0o!",
+		"x = 3, y = 1\n3q!",
+		"x = 3, y = 1\n3o3!",
+		"x = 3, y = 1\n3o2",
+	} {
+		if _, err := NewLifeFromRLE(strings.NewReader(rle)); err == nil {
+			t.Errorf("NewLifeFromRLE(%q) = nil error, want error", rle)
+		}
+	}
+}
+
+func TestNewLifeFromRLETooLarge(t *testing.T) {
+	for _, rle := range []string{
+		"x = 1000000000, y = 1000000000\n!",
+		"x = 4097, y = 4096\n!",
+		"x = 1, y = 16777217\n!",
+	} {
+		_, err := NewLifeFromRLE(strings.NewReader(rle))
+		if err == nil || !strings.Contains(err.Error(), "exceeds") {
+			t.Errorf("NewLifeFromRLE(%q) = %v, want error of too large pattern", rle, err)
+		}
+	}
+	l, err := NewLifeFromRLE(strings.NewReader("x = 4096, y = 4096\no!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Population() != 1 {
+		t.Errorf("population %d, want 1", l.Population())
+	}
+}
+
+func TestNewLifeFromFileRLE(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gun.rle")
+	if err := ioutil.WriteFile(path, []byte(gosperGunRLE), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLifeFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := patternField(library["gosper-gun"]); !l.cur.Equal(want) {
+		t.Error("gun.rle is not read as RLE")
+	}
+}
+
+func TestRLERoundTrip(t *testing.T) {
+	for _, name := range []string{"glider", "gosper-gun", "pulsar", "acorn"} {
+		f := patternField(library[name])
+		var buf strings.Builder
+		if err := f.WriteRLE(&buf); err != nil {
+			t.Fatal(err)
+		}
+		l, err := NewLifeFromRLE(strings.NewReader(buf.String()))
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, buf.String())
+		}
+		if !l.cur.Equal(f) {
+			t.Errorf("%s: round trip differs\n%s", name, buf.String())
+		}
+	}
+}
+
+func TestWriteRLERandom(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		l, err := NewRandomLife(30, 90, 0.3, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Run(100)
+
+		// load the field written as text, and save and load it as RLE.
+		var text strings.Builder
+		if _, err := l.cur.WriteTo(&text); err != nil {
+			t.Fatal(err)
+		}
+		tl, err := newLifeFromText(strings.NewReader(text.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rle strings.Builder
+		if err := tl.cur.WriteRLE(&rle); err != nil {
+			t.Fatal(err)
+		}
+		for i, line := range strings.Split(rle.String(), "\n")[1:] {
+			if len(line) > rleLineLen {
+				t.Errorf("seed %d: line %d has %d characters, want at most %d", seed, i+2, len(line), rleLineLen)
+			}
+		}
+		rl, err := NewLifeFromRLE(strings.NewReader(rle.String()))
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if !rl.cur.Equal(l.cur) {
+			t.Errorf("seed %d: round trip differs", seed)
+		}
+	}
+}
+
+func TestWriteRLE(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		want string
+	}{
+		{
+			name: "empty",
+			rows: []string{"...", "..."},
+			want: "x = 3, y = 2, rule = B3/S23\n!\n",
+		},
+		{
+			name: "trailing dead cells and rows",
+			rows: []string{"....", ".oo.", "o...", "....", "...."},
+			want: "x = 4, y = 5, rule = B3/S23\n$b2o$o!\n",
+		},
+		{
+			name: "empty rows between",
+			rows: []string{"o..", "...", "...", "ooo"},
+			want: "x = 3, y = 4, rule = B3/S23\no3$3o!\n",
+		},
+	}
+	for _, tt := range tests {
+		f := patternField(tt.rows)
+		var buf strings.Builder
+		if err := f.WriteRLE(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, buf.String(), tt.want)
+		}
+		l, err := NewLifeFromRLE(strings.NewReader(buf.String()))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !l.cur.Equal(f) {
+			t.Errorf("%s: round trip differs", tt.name)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule holds the numbers of live neighbors in which a dead cell is born and
+// a live cell survives, as bit masks.
+type Rule struct {
+	birth, survival uint16
+	states          int          // number of states of Generations rules, or 0 for two states
+	nbhd            Neighborhood // cells counted as neighbors
+
+	// Larger than Life rules count live cells in the neighborhood of radius,
+	// and use ranges of counts instead of birth and survival.
+	radius     int  // radius of the neighborhood, or 0 for other rules
+	middle     bool // whether the cell itself is counted
+	bmin, bmax int
+	smin, smax int
+
+	// elementary cellular automata calculate the next row from the
+	// current row by Wolfram's rule number.
+	elementary bool
+	wolfram    uint8
+
+	// MAP rules look up next state of cells in the table indexed by states
+	// of the cell and its neighbors.
+	mapped bool
+	table  [64]byte
+}
+
+// Conway is the rule of Conway's Game of Life, B3/S23.
+var Conway = Rule{birth: 1 << 3, survival: 1<<2 | 1<<3}
+
+// maxStates is the maximum number of states of Generations rules.
+const maxStates = 256
+
+// ParseRule parses rule string written in B/S notation such as "B36/S23",
+// in either order, or in the traditional S/B notation such as "23/36".
+// Duplicate neighbor counts are allowed, and either set may be empty such
+// as "B2/S" of Seeds, while empty parts such as "B2/" are malformed.
+// Generations rules are written with the number of states, such as
+// "B2/S/C3" or "/2/3", and Larger than Life rules are written as parseLtL
+// reads, such as "R5,C0,M1,S34..58,B34..45,NM". Elementary cellular
+// automata are written as "W" and Wolfram's rule number, such as "W110",
+// and MAP rules are written as parseMAP reads.
+// Rules on the hexagonal lattice are marked by "H:" prefix or "H" suffix,
+// such as "H:B2/S34" or "B2/S34H", and rules of von Neumann neighborhood
+// are marked by "V" suffix, such as "B13/S012V".
+func ParseRule(s string) (Rule, error) {
+	if strings.Contains(s, ",") {
+		return parseLtL(s)
+	}
+	if t := strings.TrimSpace(s); strings.HasPrefix(t, "MAP") {
+		return parseMAP(t)
+	}
+	rs := strings.ToUpper(strings.TrimSpace(s))
+	if strings.HasPrefix(rs, "W") {
+		return parseWolfram(rs)
+	}
+	nbhd := Moore
+	if strings.HasPrefix(rs, "H:") {
+		rs = rs[2:] + "H"
+	}
+	for n, suffix := range neighborhoodSuffixes {
+		if strings.HasSuffix(rs, suffix) {
+			rs, nbhd = strings.TrimSuffix(rs, suffix), n
+			break
+		}
+	}
+	parts := strings.Split(rs, "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return Rule{}, fmt.Errorf("rule %q: want B.../S... notation", s)
+	}
+	if isCounts(parts[0]) && isCounts(parts[1]) {
+		parts[0], parts[1] = "S"+parts[0], "B"+parts[1]
+		if len(parts) == 3 {
+			parts[2] = "C" + parts[2]
+		}
+	}
+	var r Rule
+	var hasB, hasS bool
+	for _, p := range parts {
+		if p == "" {
+			return Rule{}, fmt.Errorf("rule %q: want B.../S... notation", s)
+		}
+		var mask *uint16
+		switch {
+		case p[0] == 'B' && !hasB:
+			mask, hasB = &r.birth, true
+		case p[0] == 'S' && !hasS:
+			mask, hasS = &r.survival, true
+		case (p[0] == 'C' || p[0] == 'G') && r.states == 0:
+			n, err := strconv.Atoi(p[1:])
+			if err != nil || n < 2 || n > maxStates {
+				return Rule{}, fmt.Errorf("rule %q: number of states must be 2 to %d", s, maxStates)
+			}
+			r.states = n
+			continue
+		default:
+			return Rule{}, fmt.Errorf("rule %q: want B.../S... notation", s)
+		}
+		for _, c := range p[1:] {
+			if c < '0' || c > '8' {
+				return Rule{}, fmt.Errorf("rule %q: invalid neighbor count %q", s, c)
+			}
+			*mask |= 1 << uint(c-'0')
+		}
+	}
+	if !hasB || !hasS {
+		return Rule{}, fmt.Errorf("rule %q: want B.../S... notation", s)
+	}
+	if r.states == 2 {
+		r.states = 0
+	}
+	return r.WithNeighborhood(nbhd)
+}
+
+// isCounts reports whether s consists of digits only. The empty string is
+// also treated as counts, such as survival of "/2".
+func isCounts(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the rule in B/S notation, or in the notation of Larger
+// than Life rules.
+func (r Rule) String() string {
+	if r.radius > 0 {
+		return r.ltlString()
+	}
+	if r.elementary {
+		return "W" + strconv.Itoa(int(r.wolfram))
+	}
+	if r.mapped {
+		return r.mapString()
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('B')
+	writeCounts(&buf, r.birth)
+	buf.WriteString("/S")
+	writeCounts(&buf, r.survival)
+	if r.states > 2 {
+		fmt.Fprintf(&buf, "/C%d", r.states)
+	}
+	buf.WriteString(neighborhoodSuffixes[r.nbhd])
+	return buf.String()
+}
+
+func writeCounts(buf *bytes.Buffer, mask uint16) {
+	for n := 0; n <= 8; n++ {
+		if mask&(1<<uint(n)) != 0 {
+			buf.WriteByte(byte('0' + n))
+		}
+	}
+}
+
+// birthFromEmpty reports whether dead cells are born without any live
+// cells around, such as by B0, so that empty fields do not stay empty.
+func (r Rule) birthFromEmpty() bool {
+	switch {
+	case r.elementary:
+		return r.wolfram&1 != 0
+	case r.mapped:
+		return r.table[0]&0x80 != 0
+	case r.radius > 0:
+		return r.bmin == 0
+	}
+	return r.birth&1 != 0
+}
+
+// next returns if a cell will be alive in next generation when it has n live neighbors.
+func (r Rule) next(alive bool, n int) bool {
+	if alive {
+		return r.survival&(1<<uint(n)) != 0
+	}
+	return r.birth&(1<<uint(n)) != 0
+}
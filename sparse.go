@@ -0,0 +1,185 @@
+package main
+
+import "errors"
+
+// SparseField is a lifegame field holding only the coordinates of live
+// cells, so that a few patterns drifting across a vast empty space are
+// cheap. The field is an unbounded plane, or h x w cells of torus or fixed
+// topology made by NewSparseFieldBounded. Next visits only live cells and
+// their neighbors.
+type SparseField struct {
+	rule  Rule
+	cells map[[2]int]struct{}
+	gen   int
+
+	bounded bool
+	h, w    int
+	topo    Topology
+}
+
+// NewSparseField returns SparseField starting from the cells and the rule
+// of f. The cell at (r, c) in f is placed at (r, c) in the plane.
+func NewSparseField(f *Field) (*SparseField, error) {
+	if f.rule.birth&1 != 0 {
+		return nil, errors.New("sparse: rules with B0 are not supported")
+	}
+	if f.rule.states > 2 || f.rule.nbhd != Moore || f.rule.mapped || f.rule.elementary || f.rule.radius > 0 {
+		return nil, errors.New("sparse: only two state Moore rules are supported")
+	}
+	s := &SparseField{rule: f.rule, cells: make(map[[2]int]struct{})}
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			if f.get(i, j) {
+				s.cells[[2]int{i, j}] = struct{}{}
+			}
+		}
+	}
+	return s, nil
+}
+
+// NewSparseFieldBounded is like NewSparseField but keeps the size and the
+// topology of f, so that it calculates the same generations as f.
+// Mirror topology is not supported.
+func NewSparseFieldBounded(f *Field) (*SparseField, error) {
+	if f.topo == Mirror {
+		return nil, errors.New("sparse: mirror topology is not supported")
+	}
+	s, err := NewSparseField(f)
+	if err != nil {
+		return nil, err
+	}
+	s.bounded, s.h, s.w, s.topo = true, f.h, f.w, f.topo
+	return s, nil
+}
+
+// cell returns the key of the cell at (r, c) following the topology of
+// bounded fields, and reports false when it is outside of fixed field.
+func (s *SparseField) cell(r, c int) ([2]int, bool) {
+	if !s.bounded {
+		return [2]int{r, c}, true
+	}
+	coord := topologyCoords[s.topo]
+	r, rok := coord(r, s.h)
+	c, cok := coord(c, s.w)
+	return [2]int{r, c}, rok && cok
+}
+
+// Alive reports whether the cell at (r, c) is alive.
+func (s *SparseField) Alive(r, c int) bool {
+	p, ok := s.cell(r, c)
+	if !ok {
+		return false
+	}
+	_, ok = s.cells[p]
+	return ok
+}
+
+// Set sets cell's status. Cells outside of fixed field are ignored.
+func (s *SparseField) Set(r, c int, b bool) {
+	p, ok := s.cell(r, c)
+	switch {
+	case !ok:
+	case b:
+		s.cells[p] = struct{}{}
+	default:
+		delete(s.cells, p)
+	}
+}
+
+// LiveNeighbors returns the number of live cells among the eight cells
+// around the cell of r & c.
+func (s *SparseField) LiveNeighbors(r, c int) int {
+	alive := 0
+	for i := -1; i <= 1; i++ {
+		for j := -1; j <= 1; j++ {
+			if (i != 0 || j != 0) && s.Alive(r+i, c+j) {
+				alive++
+			}
+		}
+	}
+	return alive
+}
+
+// NextGen returns the status of the cell of r & c in next generation.
+func (s *SparseField) NextGen(r, c int) bool {
+	return s.rule.next(s.Alive(r, c), s.LiveNeighbors(r, c))
+}
+
+// Population returns the number of live cells.
+func (s *SparseField) Population() int {
+	return len(s.cells)
+}
+
+// Generation returns the number of generations advanced.
+func (s *SparseField) Generation() int {
+	return s.gen
+}
+
+// Next advances the field by one generation. Live neighbors are counted
+// from each live cell, so cells far from any live cell are never visited.
+func (s *SparseField) Next() {
+	counts := make(map[[2]int]int, 8*len(s.cells))
+	for p := range s.cells {
+		for i := -1; i <= 1; i++ {
+			for j := -1; j <= 1; j++ {
+				if i == 0 && j == 0 {
+					continue
+				}
+				if q, ok := s.cell(p[0]+i, p[1]+j); ok {
+					counts[q]++
+				}
+			}
+		}
+	}
+	next := make(map[[2]int]struct{}, len(s.cells))
+	for p, n := range counts {
+		if _, alive := s.cells[p]; s.rule.next(alive, n) {
+			next[p] = struct{}{}
+		}
+	}
+	// live cells without live neighbors are not counted above.
+	if s.rule.next(true, 0) {
+		for p := range s.cells {
+			if _, ok := counts[p]; !ok {
+				next[p] = struct{}{}
+			}
+		}
+	}
+	s.cells = next
+	s.gen++
+}
+
+// Bounds returns the bounding box of live cells, from (top, left) to
+// (bottom-1, right-1). It returns all zeros when there are no live cells.
+func (s *SparseField) Bounds() (top, left, bottom, right int) {
+	first := true
+	for p := range s.cells {
+		if first || p[0] < top {
+			top = p[0]
+		}
+		if first || p[1] < left {
+			left = p[1]
+		}
+		if first || p[0] >= bottom {
+			bottom = p[0] + 1
+		}
+		if first || p[1] >= right {
+			right = p[1] + 1
+		}
+		first = false
+	}
+	return top, left, bottom, right
+}
+
+// Field returns a field of height x width cells, holding the cells of the
+// plane from (top, left).
+func (s *SparseField) Field(top, left, height, width int) *Field {
+	f := NewField(height, width)
+	f.rule = s.rule
+	for p := range s.cells {
+		if r, c := p[0]-top, p[1]-left; r >= 0 && r < height && c >= 0 && c < width {
+			f.put(r, c, true)
+		}
+	}
+	return f
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSparseFieldGlider(t *testing.T) {
+	f := patternField([]string{
+		".o.",
+		"..o",
+		"ooo",
+	})
+	s, err := NewSparseField(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for g := 0; g < 8; g++ {
+		s.Next()
+	}
+	if s.Population() != 5 || s.Generation() != 8 {
+		t.Fatalf("got population %d at generation %d, want 5 at 8", s.Population(), s.Generation())
+	}
+	if top, left, bottom, right := s.Bounds(); top != 2 || left != 2 || bottom != 5 || right != 5 {
+		t.Errorf("Bounds() = %d, %d, %d, %d, want 2, 2, 5, 5", top, left, bottom, right)
+	}
+	if !s.Field(2, 2, 3, 3).Equal(f) {
+		t.Error("glider did not move by 2 cells")
+	}
+}
+
+func TestSparseFieldCrossCheck(t *testing.T) {
+	rules := []string{"B3/S23", "B36/S23", "B3/S012345678", "B2/S"}
+	sizes := [][2]int{{1, 1}, {1, 5}, {2, 3}, {17, 23}, {40, 70}}
+	for seed := int64(0); seed < 5; seed++ {
+		for _, rule := range rules {
+			r, err := ParseRule(rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, topo := range []Topology{Torus, Fixed} {
+				for _, size := range sizes {
+					h, w := size[0], size[1]
+					l, err := NewRandomLife(h, w, 0.3, seed)
+					if err != nil {
+						t.Fatal(err)
+					}
+					l.SetRule(r)
+					l.SetTopology(topo)
+					s, err := NewSparseFieldBounded(l.cur)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for g := 0; g < 30; g++ {
+						l.Next()
+						s.Next()
+						if !s.Field(0, 0, h, w).Equal(l.cur) || s.Population() != l.Population() {
+							t.Fatalf("%v %v %dx%d seed %d: generation %d differs", rule, topo, h, w, seed, l.gen)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSparseFieldUnsupported(t *testing.T) {
+	for _, rule := range []string{"B0/S8", "B2/S/C3", "B2/S34H", "R2,C0,M1,S5..9,B7..8,NM"} {
+		r, err := ParseRule(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := NewField(3, 3)
+		f.SetRule(r)
+		if _, err := NewSparseField(f); err == nil {
+			t.Errorf("NewSparseField with %v succeeded", rule)
+		}
+	}
+	f := NewField(3, 3)
+	f.SetTopology(Mirror)
+	if _, err := NewSparseFieldBounded(f); err == nil {
+		t.Error("NewSparseFieldBounded with mirror topology succeeded")
+	}
+}
+
+// BenchmarkSparseField compares SparseField and Field calculating the
+// first generation of random 2048x2048 torus of several densities.
+func BenchmarkSparseField(b *testing.B) {
+	for _, density := range []float64{0.001, 0.01, 0.03, 0.1} {
+		l, err := NewRandomLife(2048, 2048, density, 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		l.SetWorkers(1)
+		b.Run(fmt.Sprintf("density=%v/sparse", density), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				s, err := NewSparseFieldBounded(l.cur)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				s.Next()
+			}
+		})
+		b.Run(fmt.Sprintf("density=%v/dense", density), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				c := l.Clone()
+				b.StartTimer()
+				c.Next()
+			}
+		})
+	}
+}
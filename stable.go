@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// stableHistory is the number of recent generations remembered to detect
+// cycles, which is also the longest period that can be detected.
+const stableHistory = 256
+
+// cycleDetector keeps hashes of recent generations in a ring buffer.
+type cycleDetector struct {
+	hashes [stableHistory]uint64
+	gens   [stableHistory]int
+	n      int // number of hashes in the ring buffer
+	next   int // index to store the next hash
+}
+
+// observe returns the period when the current generation of l equals to one
+// of the recent generations, or records it and returns 0 otherwise.
+// States are compared by their hashes.
+func (d *cycleDetector) observe(l *Life) int {
+	h := l.cur.hash()
+	for i := 0; i < d.n; i++ {
+		if d.hashes[i] == h {
+			return l.gen - d.gens[i]
+		}
+	}
+	d.hashes[d.next], d.gens[d.next] = h, l.gen
+	d.next = (d.next + 1) % stableHistory
+	if d.n < stableHistory {
+		d.n++
+	}
+	return 0
+}
+
+// RunUntilStable steps generations until the field revisits one of the
+// recent states, or maxGen generations are stepped. It returns the
+// generation where the cycle was detected and its period, which is 1 for
+// still lifes. period is 0 when no cycle was found.
+func (l *Life) RunUntilStable(maxGen int) (gen int, period int) {
+	var d cycleDetector
+	for i := 0; ; i++ {
+		if p := d.observe(l); p > 0 {
+			return l.gen, p
+		}
+		if i == maxGen {
+			return l.gen, 0
+		}
+		l.Next()
+	}
+}
+
+// hash returns FNV-1a hash of the cells of the field.
+func (f *Field) hash() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for i := 0; i < f.h; i++ {
+		var word uint64
+		for j := 0; j < f.w; j++ {
+			if f.get(i, j) {
+				word |= 1 << uint(j&63)
+			}
+			if j&63 == 63 || j == f.w-1 {
+				binary.LittleEndian.PutUint64(buf[:], word)
+				h.Write(buf[:])
+				word = 0
+			}
+		}
+	}
+	// dying cells are part of the state of Generations rules.
+	for _, row := range f.dying {
+		h.Write(row)
+	}
+	return h.Sum64()
+}
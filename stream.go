@@ -0,0 +1,26 @@
+package main
+
+import "context"
+
+// streamBuffer is the capacity of channels returned by Stream.
+const streamBuffer = 16
+
+// Stream advances generations in a goroutine and emits a copy of each
+// generation, starting from current one, on the returned channel. The
+// channel is closed when ctx is cancelled. l must not be used by others
+// until then.
+func (l *Life) Stream(ctx context.Context) <-chan *Field {
+	ch := make(chan *Field, streamBuffer)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case ch <- l.cur.Clone():
+			case <-ctx.Done():
+				return
+			}
+			l.Next()
+		}
+	}()
+	return ch
+}
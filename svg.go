@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Default colors of SVG written by WriteSVG.
+const (
+	SVGFill       = "black"
+	SVGBackground = "white"
+)
+
+// WriteSVG writes the field as SVG image where each live cell is drawn as
+// cellSize x cellSize black square on white background.
+func (f *Field) WriteSVG(w io.Writer, cellSize int) error {
+	return f.WriteSVGWith(w, cellSize, SVGFill, SVGBackground, "")
+}
+
+// WriteSVGWith is like WriteSVG but uses fill for live cells and background
+// for dead cells. Live cells are outlined with stroke unless it is empty.
+// Consecutive live cells in a row are merged into one rect to keep the
+// output small.
+func (f *Field) WriteSVGWith(w io.Writer, cellSize int, fill, background, stroke string) error {
+	if cellSize <= 0 {
+		return errors.New("cell size must be positive")
+	}
+	bw := bufio.NewWriter(w)
+	width, height := f.w*cellSize, f.h*cellSize
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", width, height, svgEscape(background))
+	fmt.Fprintf(bw, `<g fill="%s"`, svgEscape(fill))
+	if stroke != "" {
+		fmt.Fprintf(bw, ` stroke="%s"`, svgEscape(stroke))
+	}
+	bw.WriteString(">\n")
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; {
+			if !f.get(i, j) {
+				j++
+				continue
+			}
+			k := j
+			for k < f.w && f.get(i, k) {
+				k++
+			}
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d"/>`+"\n",
+				j*cellSize, i*cellSize, (k-j)*cellSize, cellSize)
+			j = k
+		}
+	}
+	bw.WriteString("</g>\n</svg>\n")
+	return bw.Flush()
+}
+
+// svgEscape escapes s to be used as attribute value.
+func svgEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// saveSVG writes the field as SVG image to the file of path.
+func saveSVG(path string, f *Field, cellSize int) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f.WriteSVG(file, cellSize); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type svgRect struct {
+	X      int `xml:"x,attr"`
+	Y      int `xml:"y,attr"`
+	Width  int `xml:"width,attr"`
+	Height int `xml:"height,attr"`
+}
+
+type svgImage struct {
+	Width   int       `xml:"width,attr"`
+	Height  int       `xml:"height,attr"`
+	ViewBox string    `xml:"viewBox,attr"`
+	Rects   []svgRect `xml:"rect"`
+	Group   struct {
+		Fill   string    `xml:"fill,attr"`
+		Stroke string    `xml:"stroke,attr"`
+		Rects  []svgRect `xml:"rect"`
+	} `xml:"g"`
+}
+
+func TestWriteSVG(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []string
+		rects []svgRect // live cells in units of cells
+	}{
+		{"glider", library["glider"], []svgRect{{1, 0, 1, 1}, {2, 1, 1, 1}, {0, 2, 3, 1}}},
+		{"block", library["block"], []svgRect{{0, 0, 2, 1}, {0, 1, 2, 1}}},
+		{"runs", []string{"oo.ooo.", ".......", "o.o.o.o"}, []svgRect{
+			{0, 0, 2, 1}, {3, 0, 3, 1}, {0, 2, 1, 1}, {2, 2, 1, 1}, {4, 2, 1, 1}, {6, 2, 1, 1},
+		}},
+		{"empty", []string{"...", "..."}, nil},
+	}
+	const size = 10
+	for _, tt := range tests {
+		f := patternField(tt.rows)
+		var buf bytes.Buffer
+		if err := f.WriteSVG(&buf, size); err != nil {
+			t.Fatal(err)
+		}
+		var img svgImage
+		if err := xml.Unmarshal(buf.Bytes(), &img); err != nil {
+			t.Fatalf("%s: %v\n%s", tt.name, err, buf.String())
+		}
+		w, h := f.w*size, f.h*size
+		if img.Width != w || img.Height != h || img.ViewBox != fmt.Sprintf("0 0 %d %d", w, h) {
+			t.Errorf("%s: svg of %dx%d, viewBox %q", tt.name, img.Width, img.Height, img.ViewBox)
+		}
+		if want := []svgRect{{0, 0, w, h}}; !reflect.DeepEqual(img.Rects, want) {
+			t.Errorf("%s: background %v, want %v", tt.name, img.Rects, want)
+		}
+		var want []svgRect
+		for _, r := range tt.rects {
+			want = append(want, svgRect{r.X * size, r.Y * size, r.Width * size, r.Height * size})
+		}
+		if !reflect.DeepEqual(img.Group.Rects, want) {
+			t.Errorf("%s: got %d rects %v, want %d rects %v", tt.name, len(img.Group.Rects), img.Group.Rects, len(want), want)
+		}
+		if img.Group.Fill != SVGFill || img.Group.Stroke != "" {
+			t.Errorf("%s: fill %q, stroke %q", tt.name, img.Group.Fill, img.Group.Stroke)
+		}
+	}
+}
+
+func TestWriteSVGWith(t *testing.T) {
+	var buf bytes.Buffer
+	fill, stroke := `url("#a&b")`, "<gray>"
+	if err := patternField(library["glider"]).WriteSVGWith(&buf, 1, fill, "none", stroke); err != nil {
+		t.Fatal(err)
+	}
+	var img svgImage
+	if err := xml.Unmarshal(buf.Bytes(), &img); err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+	if img.Group.Fill != fill || img.Group.Stroke != stroke {
+		t.Errorf("fill %q, stroke %q, want %q, %q", img.Group.Fill, img.Group.Stroke, fill, stroke)
+	}
+	if err := NewField(1, 1).WriteSVG(&buf, 0); err == nil {
+		t.Error("WriteSVG with cell size 0 succeeded")
+	}
+}
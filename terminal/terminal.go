@@ -0,0 +1,102 @@
+// Package terminal switches terminals into full-screen mode for lifegame:
+// the alternate screen buffer, hidden cursor and unbuffered keyboard input.
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ANSI escape sequences used by Terminal.
+const (
+	EnterAltScreen = "\x1b[?1049h"
+	ExitAltScreen  = "\x1b[?1049l"
+	HideCursor     = "\x1b[?25l"
+	ShowCursor     = "\x1b[?25h"
+	reverse        = "\x1b[7m"
+	reset          = "\x1b[0m"
+	eraseLine      = "\x1b[K"
+)
+
+// ErrNotSupported is returned by Open and Size on platforms where they are
+// not implemented.
+var ErrNotSupported = errors.New("terminal: not supported on this platform")
+
+// Terminal is a terminal in full-screen mode.
+type Terminal struct {
+	in    *os.File
+	out   io.Writer
+	saved *state // state of in before Open
+	keys  chan byte
+	once  sync.Once
+}
+
+// Open switches the terminal into the alternate screen buffer and raw mode,
+// and hides the cursor. Keys are read from in, which must be a terminal,
+// and output is written to out. Raw mode here disables echo and line
+// buffering only: output processing and signals such as Ctrl-C are kept,
+// so that the program can restore the terminal on interrupt.
+// Callers must call Restore before exit.
+func Open(in *os.File, out io.Writer) (*Terminal, error) {
+	saved, err := makeRaw(in)
+	if err != nil {
+		return nil, err
+	}
+	t := &Terminal{in: in, out: out, saved: saved, keys: make(chan byte, 16)}
+	if _, err := io.WriteString(out, EnterAltScreen+HideCursor); err != nil {
+		restore(in, saved)
+		return nil, err
+	}
+	go t.readKeys()
+	return t, nil
+}
+
+// readKeys sends bytes read from the terminal to keys until read fails.
+func (t *Terminal) readKeys() {
+	defer close(t.keys)
+	var buf [1]byte
+	for {
+		n, err := t.in.Read(buf[:])
+		if err != nil {
+			return
+		}
+		if n == 1 {
+			t.keys <- buf[0]
+		}
+	}
+}
+
+// Keys returns the channel of keys pressed. It is closed when reading keys
+// fails.
+func (t *Terminal) Keys() <-chan byte {
+	return t.keys
+}
+
+// Status writes text as the status line at the cursor in reverse video.
+func (t *Terminal) Status(text string) error {
+	_, err := io.WriteString(t.out, StatusLine(text))
+	return err
+}
+
+// StatusLine returns escape sequences to write text as the status line at
+// the row of the cursor in reverse video.
+func StatusLine(text string) string {
+	return fmt.Sprintf("\r%s%s%s%s", reverse, text, reset, eraseLine)
+}
+
+// Restore shows the cursor, leaves the alternate screen buffer and restores
+// the mode of the terminal before Open. It is safe to call Restore more than
+// once.
+func (t *Terminal) Restore() error {
+	var err error
+	t.once.Do(func() {
+		_, err = io.WriteString(t.out, ShowCursor+ExitAltScreen)
+		if rerr := restore(t.in, t.saved); err == nil {
+			err = rerr
+		}
+	})
+	return err
+}
@@ -0,0 +1,101 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+// openPty returns the master and slave of a new pseudo terminal.
+func openPty(t *testing.T) (master, slave *os.File) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	var unlock int32
+	if err := ioctl(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		master.Close()
+		t.Skip(err)
+	}
+	var n uint32
+	if err := ioctl(master, syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		master.Close()
+		t.Skip(err)
+	}
+	slave, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		t.Skip(err)
+	}
+	return master, slave
+}
+
+func lflag(t *testing.T, f *os.File) uint32 {
+	var s syscall.Termios
+	if err := ioctl(f, ioctlGetTermios, unsafe.Pointer(&s)); err != nil {
+		t.Fatal(err)
+	}
+	return s.Lflag
+}
+
+func TestOpenRestore(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+	before := lflag(t, slave)
+	if before&(syscall.ECHO|syscall.ICANON) == 0 {
+		t.Fatalf("new pty is already raw: lflag %#x", before)
+	}
+
+	var buf bytes.Buffer
+	term, err := Open(slave, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), EnterAltScreen+HideCursor; got != want {
+		t.Errorf("Open wrote %q, want %q", got, want)
+	}
+	if raw := lflag(t, slave); raw&(syscall.ECHO|syscall.ICANON) != 0 {
+		t.Errorf("Open left echo or line buffering on: lflag %#x", raw)
+	}
+
+	if _, err := master.Write([]byte("q")); err != nil {
+		t.Fatal(err)
+	}
+	if k := <-term.Keys(); k != 'q' {
+		t.Errorf("key = %q, want 'q'", k)
+	}
+
+	buf.Reset()
+	for i := 0; i < 2; i++ {
+		if err := term.Restore(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := buf.String(), ShowCursor+ExitAltScreen; got != want {
+		t.Errorf("Restore wrote %q, want %q", got, want)
+	}
+	if after := lflag(t, slave); after != before {
+		t.Errorf("Restore left lflag %#x, want %#x", after, before)
+	}
+}
+
+func TestSize(t *testing.T) {
+	master, slave := openPty(t)
+	defer master.Close()
+	defer slave.Close()
+	ws := struct{ Row, Col, Xpixel, Ypixel uint16 }{Row: 24, Col: 80}
+	if err := ioctl(master, syscall.TIOCSWINSZ, unsafe.Pointer(&ws)); err != nil {
+		t.Fatal(err)
+	}
+	rows, cols, err := Size(slave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 24 || cols != 80 {
+		t.Errorf("Size = %d x %d, want 24 x 80", rows, cols)
+	}
+}
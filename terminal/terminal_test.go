@@ -0,0 +1,50 @@
+package terminal
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestStatusLine(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", "\r\x1b[7m\x1b[0m\x1b[K"},
+		{"gen 42", "\r\x1b[7mgen 42\x1b[0m\x1b[K"},
+		{"100%", "\r\x1b[7m100%\x1b[0m\x1b[K"},
+	}
+	for _, tt := range tests {
+		if got := StatusLine(tt.text); got != tt.want {
+			t.Errorf("StatusLine(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		var buf bytes.Buffer
+		term := &Terminal{out: &buf}
+		if err := term.Status(tt.text); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("Status(%q) wrote %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestOpenNotTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	var buf bytes.Buffer
+	if _, err := Open(r, &buf); err == nil {
+		t.Fatal("Open of a pipe succeeded")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Open of a pipe wrote %q", buf.String())
+	}
+	if _, _, err := Size(r); err == nil {
+		t.Error("Size of a pipe succeeded")
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package terminal
+
+import "os"
+
+type state struct{}
+
+func makeRaw(f *os.File) (*state, error) {
+	return nil, ErrNotSupported
+}
+
+func restore(f *os.File, s *state) error {
+	return nil
+}
+
+// Size returns the number of rows and columns of the terminal f.
+func Size(f *os.File) (rows, cols int, err error) {
+	return 0, 0, ErrNotSupported
+}
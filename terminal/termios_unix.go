@@ -0,0 +1,55 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package terminal
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+type state struct {
+	termios syscall.Termios
+}
+
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// makeRaw disables echo and line buffering of f, and returns the state
+// before that.
+func makeRaw(f *os.File) (*state, error) {
+	var s state
+	if err := ioctl(f, ioctlGetTermios, unsafe.Pointer(&s.termios)); err != nil {
+		return nil, err
+	}
+	raw := s.termios
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(f, ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// restore sets the state of f back to s.
+func restore(f *os.File, s *state) error {
+	return ioctl(f, ioctlSetTermios, unsafe.Pointer(&s.termios))
+}
+
+// Size returns the number of rows and columns of the terminal f.
+func Size(f *os.File) (rows, cols int, err error) {
+	var ws struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}
+	if err := ioctl(f, syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Row), int(ws.Col), nil
+}
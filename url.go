@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+// FetchTimeout is the default timeout to fetch pattern over HTTP.
+const FetchTimeout = 30 * time.Second
+
+// maxDownloadSize is the maximum size of pattern fetched over HTTP.
+const maxDownloadSize = 10 << 20
+
+// NewLifeFromURL create new lifegame buffer from pattern fetched from rawurl
+// over HTTP(S). The format is detected from the extension of the URL, or
+// from the content if the extension is unknown.
+func NewLifeFromURL(rawurl string, timeout time.Duration) (*Life, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", rawurl, resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		// web pages about the pattern are often given instead of the pattern itself.
+		if mt, _, _ := mime.ParseMediaType(ct); mt == "text/html" {
+			return nil, fmt.Errorf("%s: unexpected content type %s", rawurl, mt)
+		}
+	}
+	if resp.ContentLength > maxDownloadSize {
+		return nil, fmt.Errorf("%s: %v", rawurl, errPatternTooLarge)
+	}
+
+	lr := &limitReader{r: resp.Body, n: maxDownloadSize}
+	l, err := readPattern(bufio.NewReader(lr), formatFromExt(path.Base(u.Path)))
+	if lr.err != nil {
+		err = lr.err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", rawurl, err)
+	}
+	return l, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewLifeFromURL(t *testing.T) {
+	gun, err := ioutil.ReadFile(filepath.Join("testdata", "gosperglidergun.rle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gunGz, err := ioutil.ReadFile(filepath.Join("testdata", "gosperglidergun.rle.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gun.rle", func(w http.ResponseWriter, r *http.Request) { w.Write(gun) })
+	mux.HandleFunc("/gun.rle.gz", func(w http.ResponseWriter, r *http.Request) { w.Write(gunGz) })
+	// no extension, so the format is detected from the content.
+	mux.HandleFunc("/pattern", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(gun)
+	})
+	mux.HandleFunc("/page.rle", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html></html>"))
+	})
+	mux.HandleFunc("/large.rle", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("#C comment\n"), maxDownloadSize/10))
+	})
+	mux.HandleFunc("/slow.rle", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.Write(gun)
+	})
+	ts := httptest.NewServer(mux)
+
+	want := patternField(library["gosper-gun"])
+	for _, path := range []string{"/gun.rle", "/gun.rle.gz", "/pattern"} {
+		l, err := NewLifeFromURL(ts.URL+path, FetchTimeout)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if !l.cur.Equal(want) {
+			t.Errorf("%s: got a different pattern", path)
+		}
+	}
+
+	tests := []struct {
+		path    string
+		timeout time.Duration
+		want    string // substring of the error
+	}{
+		{path: "/missing.rle", timeout: FetchTimeout, want: "404"},
+		{path: "/page.rle", timeout: FetchTimeout, want: "text/html"},
+		{path: "/large.rle", timeout: FetchTimeout, want: errPatternTooLarge.Error()},
+		{path: "/slow.rle", timeout: 100 * time.Millisecond, want: "Timeout"},
+	}
+	for _, tt := range tests {
+		_, err := NewLifeFromURL(ts.URL+tt.path, tt.timeout)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want error containing %q", tt.path, err, tt.want)
+		}
+	}
+
+	// network error after the server is closed.
+	ts.Close()
+	if _, err := NewLifeFromURL(ts.URL+"/gun.rle", FetchTimeout); err == nil {
+		t.Error("NewLifeFromURL from closed server succeeded")
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// videoEncoder streams frames as raw RGB video to ffmpeg, which encodes
+// them into the format of the output file.
+type videoEncoder struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	width    int // width of frames in pixels
+	height   int // height of frames in pixels
+	cellSize int
+	buf      []byte // pixels of a frame, reused for each frame
+}
+
+// newVideoEncoder starts ffmpeg writing video of h x w cells at fps frames
+// per second to the file of path. The frame size is fixed at start.
+func newVideoEncoder(path string, h, w, cellSize, fps int) (*videoEncoder, error) {
+	if cellSize <= 0 {
+		return nil, errors.New("cell size must be positive")
+	}
+	if fps <= 0 {
+		return nil, errors.New("frame rate must be positive")
+	}
+	if w <= 0 || h <= 0 || cellSize > maxImagePixels/w || cellSize > maxImagePixels/h ||
+		w*cellSize > maxImagePixels/(h*cellSize) {
+		return nil, fmt.Errorf("video of %dx%d cells by %d pixels is too large", w, h, cellSize)
+	}
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, errors.New("video: ffmpeg is not found in PATH")
+	}
+	e := &videoEncoder{width: w * cellSize, height: h * cellSize, cellSize: cellSize}
+	e.buf = make([]byte, e.width*e.height*3)
+	e.cmd = exec.Command(ffmpeg, "-loglevel", "error", "-y",
+		"-f", "rawvideo", "-pix_fmt", "rgb24",
+		"-s", strconv.Itoa(e.width)+"x"+strconv.Itoa(e.height),
+		"-r", strconv.Itoa(fps), "-i", "-",
+		// most codecs require even width and height.
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2", "-pix_fmt", "yuv420p",
+		path)
+	e.cmd.Stdout = os.Stderr
+	e.cmd.Stderr = os.Stderr
+	e.stdin, err = e.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("video: %v", err)
+	}
+	return e, nil
+}
+
+// Frame writes the field as the next frame. Cells outside of the frame are
+// dropped and the rest of the frame is filled with dead cells.
+func (e *videoEncoder) Frame(f *Field) error {
+	for y := 0; y < e.height; y++ {
+		line := e.buf[y*e.width*3 : (y+1)*e.width*3]
+		r := y / e.cellSize
+		for x := 0; x < e.width; x++ {
+			c := x / e.cellSize
+			v := byte(0xff)
+			if r < f.h && c < f.w && f.get(r, c) {
+				v = 0
+			}
+			line[3*x], line[3*x+1], line[3*x+2] = v, v, v
+		}
+	}
+	if _, err := e.stdin.Write(e.buf); err != nil {
+		// ffmpeg exited early, and the reason is in its exit status.
+		e.stdin.Close()
+		if werr := e.cmd.Wait(); werr != nil {
+			return fmt.Errorf("video: ffmpeg exited: %v", werr)
+		}
+		return fmt.Errorf("video: %v", err)
+	}
+	return nil
+}
+
+// Close finishes the input of ffmpeg and waits until it finalizes the file.
+func (e *videoEncoder) Close() error {
+	e.stdin.Close()
+	if err := e.cmd.Wait(); err != nil {
+		return fmt.Errorf("video: ffmpeg exited: %v", err)
+	}
+	return nil
+}
+
+// saveVideo writes generations frames of the run to the video file of path.
+// Frames keep the area of the first generation when the field grows.
+func saveVideo(path string, l *Life, generations, cellSize, fps int) error {
+	if generations <= 0 {
+		return errors.New("generations must be positive")
+	}
+	e, err := newVideoEncoder(path, l.cur.h, l.cur.w, cellSize, fps)
+	if err != nil {
+		return err
+	}
+	top, left := l.cur.Origin()
+	h, w := l.cur.h, l.cur.w
+	for i := 0; i < generations; i++ {
+		if err := e.Frame(l.cur.planeWindow(top, left, h, w)); err != nil {
+			return err
+		}
+		l.Next()
+	}
+	return e.Close()
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// cellsPerChar returns the number of rows and columns of cells displayed
+// in a character in render mode m.
+func (m RenderMode) cellsPerChar() (rows, cols int) {
+	switch m {
+	case HalfBlock:
+		return 2, 1
+	case Braille:
+		return 4, 2
+	}
+	return 1, 1
+}
+
+// maxZoom is the maximum zoom factor of Viewport.
+const maxZoom = 16
+
+// Viewport is the window of a field which is displayed.
+// At zoom factor n, each unit of the view shows n x n cells.
+type Viewport struct {
+	Top, Left  int // cell at the top left of the view
+	Zoom       int // number of cells along each side of a unit, 1 or more
+	Rows, Cols int // size of the view in units
+}
+
+// span returns the number of cells along the side of n units, not
+// exceeding size.
+func (v *Viewport) span(n, size int) int {
+	if n*v.Zoom > size {
+		return size
+	}
+	return n * v.Zoom
+}
+
+// Size returns the number of units of the view of f which are on the field.
+// It is smaller than Rows and Cols when the field is smaller than the view.
+func (v *Viewport) Size(f *Field) (rows, cols int) {
+	return (v.span(v.Rows, f.h) + v.Zoom - 1) / v.Zoom, (v.span(v.Cols, f.w) + v.Zoom - 1) / v.Zoom
+}
+
+// Clamp keeps the view on f. The view wraps around edges of torus, and stops
+// at edges of fixed field.
+func (v *Viewport) Clamp(f *Field) {
+	if v.Zoom < 1 {
+		v.Zoom = 1
+	}
+	if v.Zoom > maxZoom {
+		v.Zoom = maxZoom
+	}
+	v.Top = clampOrigin(v.Top, v.span(v.Rows, f.h), f.h, f.topo)
+	v.Left = clampOrigin(v.Left, v.span(v.Cols, f.w), f.w, f.topo)
+}
+
+// clampOrigin returns the origin of window of span cells on size cells.
+func clampOrigin(origin, span, size int, t Topology) int {
+	if size == 0 {
+		return 0
+	}
+	if t == Torus {
+		return (origin%size + size) % size
+	}
+	if origin > size-span {
+		origin = size - span
+	}
+	if origin < 0 {
+		origin = 0
+	}
+	return origin
+}
+
+// Pan moves the view by dr rows and dc columns of units.
+func (v *Viewport) Pan(f *Field, dr, dc int) {
+	v.Top += dr * v.Zoom
+	v.Left += dc * v.Zoom
+	v.Clamp(f)
+}
+
+// SetZoom changes the zoom factor keeping the center of the view.
+func (v *Viewport) SetZoom(f *Field, zoom int) {
+	if zoom < 1 {
+		zoom = 1
+	}
+	if zoom > maxZoom {
+		zoom = maxZoom
+	}
+	r := v.Top + v.span(v.Rows, f.h)/2
+	c := v.Left + v.span(v.Cols, f.w)/2
+	v.Zoom = zoom
+	v.CenterOn(f, r, c)
+}
+
+// CenterOn moves the view so that the cell of r & c is at its center.
+func (v *Viewport) CenterOn(f *Field, r, c int) {
+	v.Top = r - v.span(v.Rows, f.h)/2
+	v.Left = c - v.span(v.Cols, f.w)/2
+	v.Clamp(f)
+}
+
+// Recenter moves the view to the centroid of live cells, or to the center
+// of the field when there are no live cells.
+func (v *Viewport) Recenter(f *Field) {
+	n, sr, sc := 0, 0, 0
+	for i := 0; i < f.h; i++ {
+		for j := 0; j < f.w; j++ {
+			if f.get(i, j) {
+				n++
+				sr += i
+				sc += j
+			}
+		}
+	}
+	if n == 0 {
+		v.CenterOn(f, f.h/2, f.w/2)
+		return
+	}
+	v.CenterOn(f, sr/n, sc/n)
+}
+
+// cell returns the cell of r & c of the view, wrapping around edges of torus.
+func (v *Viewport) cell(f *Field, r, c int) (int, int) {
+	return (v.Top + r) % f.h, (v.Left + c) % f.w
+}
+
+// Crop returns a copy of the cells in the view of f at zoom factor 1.
+func (v *Viewport) Crop(f *Field) *Field {
+	h, w := v.span(v.Rows, f.h), v.span(v.Cols, f.w)
+	c := NewField(h, w)
+	c.rule = f.rule
+	c.topo = f.topo
+	if f.ages != nil {
+		c.ages = newAges(h, w)
+	}
+	if f.heat != nil {
+		c.heat = newHeat(h, w)
+	}
+	if f.dying != nil {
+		c.dying = newDying(h, w)
+	}
+	for i := 0; i < h; i++ {
+		for j := 0; j < w; j++ {
+			r, cc := v.cell(f, i, j)
+			c.cs[i][j] = f.get(r, cc)
+			if c.ages != nil {
+				c.ages[i][j] = f.ages[r][cc]
+			}
+			if c.heat != nil {
+				c.heat[i][j] = f.heat[r][cc]
+			}
+			if c.dying != nil {
+				c.dying[i][j] = f.dying[r][cc]
+			}
+		}
+	}
+	return c
+}
+
+// Density returns the fraction of live cells in the unit of r & c of the
+// view. Cells beyond edges of fixed field are not counted.
+func (v *Viewport) Density(f *Field, r, c int) float64 {
+	h, w := v.span(v.Rows, f.h), v.span(v.Cols, f.w)
+	n, alive := 0, 0
+	for i := r * v.Zoom; i < (r+1)*v.Zoom && i < h; i++ {
+		for j := c * v.Zoom; j < (c+1)*v.Zoom && j < w; j++ {
+			rr, cc := v.cell(f, i, j)
+			n++
+			if f.get(rr, cc) {
+				alive++
+			}
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(alive) / float64(n)
+}
+
+// densityRamp is the characters for densities of live cells from empty to full.
+const densityRamp = " .:-=+*#%@"
+
+// densityChar returns the character for density d. Any live cells are
+// displayed as some character other than space.
+func densityChar(d float64) byte {
+	i := int(d*float64(len(densityRamp)-1) + 0.999)
+	if i >= len(densityRamp) {
+		i = len(densityRamp) - 1
+	}
+	return densityRamp[i]
+}
+
+// viewLines is the number of terminal lines used other than the field: the
+// header line and the status line.
+const viewLines = 2
+
+// viewRenderer renders the view of fields which fits in the terminal.
+// When the size of the terminal is unknown, the whole field is rendered.
+type viewRenderer struct {
+	r    *DiffRenderer
+	mode RenderMode
+
+	mu         sync.Mutex
+	rows, cols int // size of the terminal, or 0 when it is unknown
+	view       Viewport
+	placed     bool   // whether the view is placed on the field
+	origin     [2]int // origin of the field where the view is placed
+}
+
+// newViewRenderer returns a renderer of views of fields in render mode m,
+// redrawing changes with r.
+func newViewRenderer(r *DiffRenderer, m RenderMode) *viewRenderer {
+	return &viewRenderer{r: r, mode: m, view: Viewport{Zoom: 1}}
+}
+
+// SetSize sets the size of the terminal in characters.
+func (v *viewRenderer) SetSize(rows, cols int) {
+	v.mu.Lock()
+	v.rows, v.cols = rows, cols
+	v.mu.Unlock()
+}
+
+// Update calls fn with the view of f under the lock, to move the view.
+func (v *viewRenderer) Update(f *Field, fn func(*Viewport)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fit()
+	v.follow(f)
+	zoom := v.view.Zoom
+	r := v.view.Top + v.view.span(v.view.Rows, f.h)/2
+	c := v.view.Left + v.view.span(v.view.Cols, f.w)/2
+	fn(&v.view)
+	if v.view.Zoom != zoom {
+		// units of the view change with zoom factor 1.
+		v.fit()
+		v.view.CenterOn(f, r, c)
+	}
+	v.view.Clamp(f)
+	v.placed = true
+}
+
+// follow moves the view by the cells added before the top left of f when
+// the field has grown, so that the view keeps showing the same cells.
+func (v *viewRenderer) follow(f *Field) {
+	top, left := f.Origin()
+	if v.placed {
+		v.view.Top += v.origin[0] - top
+		v.view.Left += v.origin[1] - left
+	}
+	v.origin = [2]int{top, left}
+}
+
+// HandleKey moves the view of f by key: h, j, k and l to pan, + and - to
+// zoom in and out, and c to recenter on live cells.
+func (v *Viewport) HandleKey(f *Field, key byte) {
+	switch key {
+	case 'h':
+		v.Pan(f, 0, -1)
+	case 'j':
+		v.Pan(f, 1, 0)
+	case 'k':
+		v.Pan(f, -1, 0)
+	case 'l':
+		v.Pan(f, 0, 1)
+	case '+', '=':
+		v.SetZoom(f, v.Zoom/2)
+	case '-':
+		v.SetZoom(f, v.Zoom*2)
+	case 'c':
+		v.Recenter(f)
+	}
+}
+
+// fit sets the size of the view from the size of the terminal.
+func (v *viewRenderer) fit() {
+	rows, cols := v.rows-viewLines, v.cols
+	if v.r.Border {
+		rows, cols = rows-2, cols-2
+	}
+	if rows < 0 {
+		rows = 0
+	}
+	if cols < 0 {
+		cols = 0
+	}
+	if v.view.Zoom <= 1 {
+		cr, cc := v.mode.cellsPerChar()
+		rows, cols = rows*cr, cols*cc
+	}
+	v.view.Rows, v.view.Cols = rows, cols
+}
+
+// Render renders the view of f.
+func (v *viewRenderer) Render(f *Field, gen int) error {
+	v.mu.Lock()
+	if v.rows <= 0 || v.cols <= 0 {
+		v.mu.Unlock()
+		return v.r.Render(f, gen)
+	}
+	v.fit()
+	v.follow(f)
+	if !v.placed {
+		v.view.CenterOn(f, f.h/2, f.w/2)
+		v.placed = true
+	}
+	v.view.Clamp(f)
+	view := v.view
+	v.mu.Unlock()
+
+	// the header describes the whole field rather than the view.
+	var buf, cells bytes.Buffer
+	if view.Zoom == 1 {
+		if err := renderHeader(&buf, v.r.Header, f, gen, ""); err != nil {
+			return err
+		}
+		view.Crop(f).render(&cells, v.r.mode, v.r.Glyphs)
+	} else {
+		if err := renderHeader(&buf, v.r.Header, f, gen, fmt.Sprintf(" (zoom 1/%v)", view.Zoom)); err != nil {
+			return err
+		}
+		rows, cols := view.Size(f)
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				cells.WriteByte(densityChar(view.Density(f, i, j)))
+			}
+			cells.WriteByte('\n')
+		}
+	}
+	if v.r.Border {
+		buf.Write(drawBorder(cells.Bytes()))
+	} else {
+		buf.Write(cells.Bytes())
+	}
+	return v.r.renderText(buf.Bytes())
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestViewportClamp(t *testing.T) {
+	tests := []struct {
+		topo      Topology
+		h, w      int // size of the field
+		top, left int
+		wantTop   int
+		wantLeft  int
+	}{
+		// 5 x 8 view on 20 x 30 field.
+		{Fixed, 20, 30, 3, 4, 3, 4},
+		{Fixed, 20, 30, -3, -1, 0, 0},
+		{Fixed, 20, 30, 15, 22, 15, 22},
+		{Fixed, 20, 30, 16, 23, 15, 22},
+		{Fixed, 20, 30, 100, -100, 15, 0},
+		{Fixed, 20, 30, -100, 100, 0, 22},
+		{Mirror, 20, 30, 100, 100, 15, 22},
+		{Torus, 20, 30, -3, -1, 17, 29},
+		{Torus, 20, 30, 20, 30, 0, 0},
+		{Torus, 20, 30, 45, 61, 5, 1},
+		{Torus, 20, 30, 18, 27, 18, 27},
+		// fields smaller than the view.
+		{Fixed, 4, 6, 2, 3, 0, 0},
+		{Fixed, 4, 6, -2, -3, 0, 0},
+		{Torus, 4, 6, -1, 7, 3, 1},
+	}
+	for _, tt := range tests {
+		f := NewField(tt.h, tt.w)
+		f.SetTopology(tt.topo)
+		v := Viewport{Top: tt.top, Left: tt.left, Zoom: 1, Rows: 5, Cols: 8}
+		v.Clamp(f)
+		if v.Top != tt.wantTop || v.Left != tt.wantLeft {
+			t.Errorf("%v %dx%d from (%d, %d): got (%d, %d), want (%d, %d)",
+				tt.topo, tt.w, tt.h, tt.top, tt.left, v.Top, v.Left, tt.wantTop, tt.wantLeft)
+		}
+	}
+}
+
+func TestViewportCrop(t *testing.T) {
+	f := patternField([]string{
+		"o.....",
+		".o....",
+		"..o...",
+		"...o..",
+	})
+	tests := []struct {
+		topo      Topology
+		top, left int
+		rows      []string
+	}{
+		{Fixed, 0, 0, []string{"o..", ".o."}},
+		{Fixed, 2, 3, []string{"...", "o.."}},
+		{Fixed, 9, 9, []string{"...", "o.."}},   // clamped to the bottom right
+		{Torus, 3, 5, []string{"...", ".o."}},   // wraps at both edges
+		{Torus, -1, -1, []string{"...", ".o."}}, // same as (3, 5)
+	}
+	for _, tt := range tests {
+		f.SetTopology(tt.topo)
+		v := Viewport{Top: tt.top, Left: tt.left, Zoom: 1, Rows: 2, Cols: 3}
+		v.Clamp(f)
+		if got := fieldRows(v.Crop(f)); strings.Join(got, "\n") != strings.Join(tt.rows, "\n") {
+			t.Errorf("%v from (%d, %d): got %q, want %q", tt.topo, tt.top, tt.left, got, tt.rows)
+		}
+	}
+
+	// fields smaller than the view are cropped to themselves.
+	for _, topo := range []Topology{Torus, Fixed} {
+		f.SetTopology(topo)
+		v := Viewport{Zoom: 1, Rows: 10, Cols: 20}
+		v.Clamp(f)
+		if rows, cols := v.Size(f); rows != 4 || cols != 6 {
+			t.Errorf("%v: view of %dx%d units, want 6x4", topo, cols, rows)
+		}
+		if !v.Crop(f).Equal(f) {
+			t.Errorf("%v: crop differs from the field", topo)
+		}
+	}
+}
+
+func TestViewRendererSize(t *testing.T) {
+	tests := []struct {
+		rows, cols int // size of the terminal
+		h, w       int // size of the field
+		mode       RenderMode
+		lines      int // lines of the field rendered
+		width      int // characters of each line
+	}{
+		{0, 0, 30, 50, ASCII, 30, 50}, // unknown size renders the whole field
+		{12, 20, 30, 50, ASCII, 10, 20},
+		{40, 80, 30, 50, ASCII, 30, 50},
+		{40, 20, 30, 50, ASCII, 30, 20},
+		{12, 80, 30, 50, ASCII, 10, 50},
+		{12, 20, 30, 50, HalfBlock, 10, 20},
+		{12, 20, 30, 50, Braille, 8, 20},
+		{1, 1, 30, 50, ASCII, 0, 0},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		r := NewDiffRenderer(&buf, tt.mode)
+		r.Header = nil
+		v := newViewRenderer(r, tt.mode)
+		v.SetSize(tt.rows, tt.cols)
+		f := NewField(tt.h, tt.w)
+		if err := v.Render(f, 0); err != nil {
+			t.Fatal(err)
+		}
+		text := strings.TrimPrefix(buf.String(), ansiHome)
+		text = strings.TrimSuffix(text, ansiEraseDown)
+		var lines []string
+		if text != "" {
+			lines = strings.Split(strings.TrimSuffix(text, ansiEraseLine+"\n"), ansiEraseLine+"\n")
+		}
+		if tt.lines == 0 {
+			// the view is empty but still rendered as a line.
+			if len(lines) > 1 || len(lines) == 1 && lines[0] != "" {
+				t.Errorf("%dx%d terminal: got %q", tt.cols, tt.rows, lines)
+			}
+			continue
+		}
+		if len(lines) != tt.lines {
+			t.Fatalf("%dx%d terminal, %dx%d field in %v: got %d lines, want %d",
+				tt.cols, tt.rows, tt.w, tt.h, tt.mode, len(lines), tt.lines)
+		}
+		for _, line := range lines {
+			if n := len([]rune(line)); n != tt.width {
+				t.Errorf("%dx%d terminal, %dx%d field in %v: got line of %d characters, want %d",
+					tt.cols, tt.rows, tt.w, tt.h, tt.mode, n, tt.width)
+				break
+			}
+		}
+	}
+}
+
+func TestViewRendererCentered(t *testing.T) {
+	r := NewDiffRenderer(&bytes.Buffer{}, ASCII)
+	r.Header = nil
+	v := newViewRenderer(r, ASCII)
+	v.SetSize(12, 20)
+	f := NewField(30, 50)
+	f.SetTopology(Fixed)
+	if err := v.Render(f, 0); err != nil {
+		t.Fatal(err)
+	}
+	if v.view.Top != 10 || v.view.Left != 15 {
+		t.Errorf("view at (%d, %d), want centered at (10, 15)", v.view.Top, v.view.Left)
+	}
+}
+
+func TestViewportPan(t *testing.T) {
+	tests := []struct {
+		topo      Topology
+		zoom      int
+		top, left int
+		dr, dc    int
+		wantTop   int
+		wantLeft  int
+	}{
+		// 10 x 10 units of view on 100 x 100 field.
+		{Fixed, 1, 50, 50, 1, -1, 51, 49},
+		{Fixed, 4, 50, 50, 1, -1, 54, 46},
+		{Fixed, 1, 0, 0, -1, -1, 0, 0},
+		{Fixed, 1, 90, 90, 1, 1, 90, 90},
+		{Fixed, 4, 58, 58, 1, 1, 60, 60},
+		{Torus, 1, 0, 0, -1, -1, 99, 99},
+		{Torus, 4, 98, 1, 1, -1, 2, 97},
+	}
+	for _, tt := range tests {
+		f := NewField(100, 100)
+		f.SetTopology(tt.topo)
+		v := Viewport{Top: tt.top, Left: tt.left, Zoom: tt.zoom, Rows: 10, Cols: 10}
+		v.Pan(f, tt.dr, tt.dc)
+		if v.Top != tt.wantTop || v.Left != tt.wantLeft {
+			t.Errorf("%v zoom %d: pan (%d, %d) from (%d, %d) to (%d, %d), want (%d, %d)",
+				tt.topo, tt.zoom, tt.dr, tt.dc, tt.top, tt.left, v.Top, v.Left, tt.wantTop, tt.wantLeft)
+		}
+	}
+}
+
+func TestViewportSetZoom(t *testing.T) {
+	tests := []struct {
+		topo      Topology
+		top, left int
+		zoom      int
+		wantZoom  int
+		wantTop   int
+		wantLeft  int
+	}{
+		// 10 x 10 units of view centered at (50, 50) of 100 x 100 field.
+		{Fixed, 45, 45, 4, 4, 30, 30},
+		{Fixed, 45, 45, 0, 1, 45, 45},
+		{Fixed, 45, 45, 100, maxZoom, 0, 0},
+		// the view is clamped after zoom at the edges.
+		{Fixed, 0, 0, 8, 8, 0, 0},
+		{Fixed, 90, 90, 8, 8, 20, 20},
+		{Fixed, 0, 90, 4, 4, 0, 60},
+		{Torus, 0, 0, 4, 4, 85, 85},
+	}
+	for _, tt := range tests {
+		f := NewField(100, 100)
+		f.SetTopology(tt.topo)
+		v := Viewport{Top: tt.top, Left: tt.left, Zoom: 1, Rows: 10, Cols: 10}
+		v.SetZoom(f, tt.zoom)
+		if v.Zoom != tt.wantZoom || v.Top != tt.wantTop || v.Left != tt.wantLeft {
+			t.Errorf("%v from (%d, %d): zoom %d to %d at (%d, %d), want %d at (%d, %d)",
+				tt.topo, tt.top, tt.left, tt.zoom, v.Zoom, v.Top, v.Left, tt.wantZoom, tt.wantTop, tt.wantLeft)
+		}
+	}
+}
+
+func TestViewportHandleKey(t *testing.T) {
+	f := NewField(100, 100)
+	f.SetTopology(Fixed)
+	v := Viewport{Top: 45, Left: 45, Zoom: 2, Rows: 10, Cols: 10}
+	for _, k := range []struct {
+		key       byte
+		zoom      int
+		top, left int
+	}{
+		{'j', 2, 47, 45},
+		{'l', 2, 47, 47},
+		{'k', 2, 45, 47},
+		{'h', 2, 45, 45},
+		{'-', 4, 35, 35},
+		{'+', 2, 45, 45},
+		{'=', 1, 50, 50},
+		{'+', 1, 50, 50},
+		{'x', 1, 50, 50},
+	} {
+		v.HandleKey(f, k.key)
+		if v.Zoom != k.zoom || v.Top != k.top || v.Left != k.left {
+			t.Errorf("key %q: zoom %d at (%d, %d), want %d at (%d, %d)",
+				k.key, v.Zoom, v.Top, v.Left, k.zoom, k.top, k.left)
+		}
+	}
+
+	f.Set(80, 20, true)
+	f.Set(82, 24, true)
+	v.HandleKey(f, 'c')
+	if v.Top != 76 || v.Left != 17 {
+		t.Errorf("recentered at (%d, %d), want (76, 17)", v.Top, v.Left)
+	}
+}
+
+func TestViewportDensity(t *testing.T) {
+	f := patternField([]string{
+		"oo.o.",
+		"o....",
+		".....",
+		"oooo.",
+		"oooo.",
+	})
+	f.SetTopology(Fixed)
+	v := Viewport{Zoom: 2, Rows: 3, Cols: 3}
+	v.Clamp(f)
+	if rows, cols := v.Size(f); rows != 3 || cols != 3 {
+		t.Fatalf("view of %dx%d units, want 3x3", cols, rows)
+	}
+	want := [3][3]float64{
+		{0.75, 0.25, 0},
+		{0.5, 0.5, 0},
+		{1, 1, 0}, // units beyond the edges count cells on the field only
+	}
+	for i := range want {
+		for j := range want[i] {
+			if d := v.Density(f, i, j); d != want[i][j] {
+				t.Errorf("density of unit (%d, %d) is %v, want %v", i, j, d, want[i][j])
+			}
+		}
+	}
+	for _, tt := range []struct {
+		d  float64
+		ch byte
+	}{{0, ' '}, {0.01, '.'}, {0.5, '+'}, {0.99, '@'}, {1, '@'}} {
+		if ch := densityChar(tt.d); ch != tt.ch {
+			t.Errorf("densityChar(%v) = %q, want %q", tt.d, ch, tt.ch)
+		}
+	}
+}